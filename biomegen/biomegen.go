@@ -0,0 +1,41 @@
+// Package biomegen finds ESLint, Prettier and Stylelint configs and
+// migrates them to Biome, as the biome_configurator command does, for use
+// from other Go programs.
+package biomegen
+
+import (
+	"context"
+
+	"biome_config_generator/internal/migrate"
+)
+
+// ConfigLocation is a directory holding ESLint, Prettier or Stylelint
+// configs, as FindConfigs reports it, along with what was detected there.
+type ConfigLocation = migrate.ConfigLocation
+
+// Options holds the settings that affect how each location is migrated. It
+// mirrors the command-line flags; its zero value detects the package runner
+// per location and writes biome.json.
+type Options = migrate.Options
+
+// FindConfigs scans root for directories holding ESLint, Prettier or
+// Stylelint configs, with the command's default scan settings: dependency,
+// build output, hidden and .gitignore'd directories are skipped. The result
+// is keyed by directory.
+func FindConfigs(root string) (map[string]*ConfigLocation, error) {
+	return migrate.FindConfigs(root)
+}
+
+// Migrate runs biome migrate for each tool configured at loc and patches the
+// resulting biome.json, as the command does for one location. What it
+// prints goes to opts.Output. The errors of all steps that failed are
+// joined into the returned error.
+func Migrate(loc *ConfigLocation, opts Options) error {
+	return migrate.Migrate(loc, opts)
+}
+
+// MigrateContext is like Migrate, but kills the biome commands when ctx is
+// done. A biome.json the interrupted migration had created is removed.
+func MigrateContext(ctx context.Context, loc *ConfigLocation, opts Options) error {
+	return migrate.MigrateContext(ctx, loc, opts)
+}
@@ -0,0 +1,70 @@
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// pathAlias maps a logical directory, the path users expect to see, to the
+// real directory holding its files.
+type pathAlias struct {
+	logical string
+	real    string
+}
+
+// loadAliases reads a JSON object mapping logical paths to real ones. Both
+// sides may be absolute or relative to root.
+func loadAliases(path, root string) ([]pathAlias, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	resolve := func(p string) string {
+		if filepath.IsAbs(p) {
+			return filepath.Clean(p)
+		}
+		return filepath.Join(root, p)
+	}
+
+	aliases := make([]pathAlias, 0, len(raw))
+	for logical, real := range raw {
+		aliases = append(aliases, pathAlias{logical: resolve(logical), real: resolve(real)})
+	}
+	return aliases, nil
+}
+
+// findAliasedConfigs scans the real directory of each alias and adds the
+// locations found there to locations, labelled with their logical path. A
+// real directory that was already found keeps its entry and just gains the
+// label. The logical paths belong to root.
+func findAliasedConfigs(locations map[string]*ConfigLocation, aliases []pathAlias, root string, opts scanOptions) error {
+	for _, alias := range aliases {
+		found, err := findConfigs(alias.real, opts)
+		if err != nil {
+			return fmt.Errorf("alias %s: %w", alias.logical, err)
+		}
+
+		for dir, loc := range found {
+			rel, err := filepath.Rel(alias.real, dir)
+			if err != nil {
+				return err
+			}
+			if existing, ok := locations[dir]; ok {
+				loc = existing
+			} else {
+				locations[dir] = loc
+			}
+			loc.LogicalDir = filepath.Join(alias.logical, rel)
+			loc.Root = root
+		}
+	}
+	return nil
+}
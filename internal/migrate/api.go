@@ -0,0 +1,73 @@
+// Package migrate implements biome_configurator: the config discovery, the
+// migration of each location and the command-line run. Package biomegen
+// exposes the part of it meant for other programs.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// FindConfigs scans root for directories holding ESLint, Prettier or
+// Stylelint configs, with the command's default scan settings: dependency,
+// build output, hidden and .gitignore'd directories are skipped. The result
+// is keyed by directory.
+func FindConfigs(root string) (map[string]*ConfigLocation, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	return findConfigs(root, scanOptions{skipDirs: defaultSkipDirs, gitignore: true})
+}
+
+// Migrate runs biome migrate for each tool configured at loc and patches the
+// resulting biome.json, as the command does for one location. What it
+// prints goes to opts.Output. The errors of all steps that failed are
+// joined into the returned error.
+func Migrate(loc *ConfigLocation, opts Options) error {
+	return MigrateContext(context.Background(), loc, opts)
+}
+
+// MigrateContext is like Migrate, but kills the biome commands when ctx is
+// done. A biome.json the interrupted migration had created is removed.
+func MigrateContext(ctx context.Context, loc *ConfigLocation, opts Options) error {
+	if opts.Runner == "" {
+		opts.Runner = runnerAuto
+	}
+	if !slices.Contains(runners, opts.Runner) {
+		return fmt.Errorf("unknown runner %q", opts.Runner)
+	}
+	if opts.Emit == "" {
+		opts.Emit = emitJSON
+	}
+	if !slices.Contains(emitFormats, opts.Emit) {
+		return fmt.Errorf("unknown emit format %q", opts.Emit)
+	}
+	if opts.backupStamp == "" {
+		opts.backupStamp = time.Now().Format(backupStampLayout)
+	}
+
+	out := &printer{w: opts.Output}
+	if out.w == nil {
+		out.w = io.Discard
+	}
+	res := migrateLocation(ctx, loc, &opts, out)
+	return errors.Join(res.errs...)
+}
+
+// DefaultSkipDirs returns the directory names the command skips unless
+// -skip-dirs replaces them. node_modules and .git are always skipped.
+func DefaultSkipDirs() []string {
+	return slices.Clone(defaultSkipDirs)
+}
+
+// ConfigFileNames returns the config file names a tool is detected by:
+// tool is "eslint", "prettier" or "stylelint".
+func ConfigFileNames(tool string) []string {
+	return slices.Clone(toolConfigFiles[tool])
+}
@@ -0,0 +1,127 @@
+package migrate
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// migrateTouchedFiles are the files in a location that biome migrate and
+// the patch step may rewrite.
+var migrateTouchedFiles = []string{"biome.json", "biome.jsonc"}
+
+// backupStampLayout is the time layout of the suffix shared by the backups
+// of one run.
+const backupStampLayout = "20060102T150405"
+
+// backupEntry pairs a file with the copy taken of it before migration.
+type backupEntry struct {
+	path string
+	copy string
+}
+
+// pendingRestore is what undoing a failed location from its backups takes:
+// the copies to put back, and the config to remove if the run created it.
+type pendingRestore struct {
+	configPath string
+	existed    bool
+	entries    []backupEntry
+}
+
+// needed reports whether there is anything to undo.
+func (r *pendingRestore) needed() bool {
+	if len(r.entries) > 0 {
+		return true
+	}
+	_, err := os.Stat(r.configPath)
+	return !r.existed && err == nil
+}
+
+// backupLocation copies each existing file biome migrate may touch in dir to
+// a sibling <name>.<stamp>.bak. Files that don't exist yet are skipped, so
+// the result may be empty.
+func backupLocation(dir, stamp string) ([]backupEntry, error) {
+	var entries []backupEntry
+	for _, name := range migrateTouchedFiles {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		copyPath := path + "." + stamp + ".bak"
+		if err := copyFile(path, copyPath); err != nil {
+			return entries, err
+		}
+		entries = append(entries, backupEntry{path: path, copy: copyPath})
+	}
+	return entries, nil
+}
+
+// restoreBackups takes dir back to its state before migration: each backed
+// up file gets its copy back, and the config at configPath is removed when
+// it didn't exist before, i.e. the migration created it.
+func restoreBackups(configPath string, existed bool, entries []backupEntry, out *printer) error {
+	for _, e := range entries {
+		if err := copyFile(e.copy, e.path); err != nil {
+			return err
+		}
+		out.infof("  Restored: %s\n", e.path)
+	}
+	if existed {
+		return nil
+	}
+	if err := os.Remove(configPath); errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	out.infof("  Removed: %s\n", configPath)
+	return nil
+}
+
+// offerRestore tells the user how to undo the failed locations of a run
+// from their backups, once every location has finished. When stdin is a
+// terminal it asks once, and on "y" restores them all with restoreBackups,
+// which also removes the biome.json files the run created. The restored
+// locations have their changes cleared, as there is nothing left to roll
+// back.
+func offerRestore(out *printer, results []*locationResult) {
+	var pending []*locationResult
+	for _, res := range results {
+		if res.restore != nil && res.restore.needed() {
+			pending = append(pending, res)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	out.errorf("\nBackups of the original files of %d failed location(s) are available:\n", len(pending))
+	for _, res := range pending {
+		out.errorf("  %s:\n", res.loc.displayDir())
+		for _, e := range res.restore.entries {
+			out.errorf("    cp %s %s\n", e.copy, e.path)
+		}
+		if !res.restore.existed {
+			out.errorf("    rm %s\n", res.restore.configPath)
+		}
+	}
+	if !isTerminal(os.Stdin) {
+		return
+	}
+
+	out.printf("Restore the original files from backup? [y/N] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+		return
+	}
+	for _, res := range pending {
+		r := res.restore
+		if err := restoreBackups(r.configPath, r.existed, r.entries, out); err != nil {
+			out.errorf("  Error restoring the backups of %s: %v\n", res.loc.displayDir(), err)
+			continue
+		}
+		res.changes = nil
+	}
+}
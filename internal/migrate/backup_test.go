@@ -0,0 +1,76 @@
+package migrate
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRestoreBackups(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		config  string
+		wantOld map[string]string
+		removed []string
+	}{
+		{
+			name:    "patched config",
+			files:   map[string]string{"biome.json": `{"old": true}`},
+			config:  "biome.json",
+			wantOld: map[string]string{"biome.json": `{"old": true}`},
+		},
+		{
+			name:    "created config",
+			config:  "biome.json",
+			removed: []string{"biome.json"},
+		},
+		{
+			name:    "renamed to biome.jsonc",
+			files:   map[string]string{"biome.json": `{"old": true}`},
+			config:  "biome.jsonc",
+			wantOld: map[string]string{"biome.json": `{"old": true}`},
+			removed: []string{"biome.jsonc"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			configPath := filepath.Join(dir, tt.config)
+			_, err := os.Stat(configPath)
+			restore := &pendingRestore{configPath: configPath, existed: err == nil}
+			if restore.entries, err = backupLocation(dir, "20240101T000000"); err != nil {
+				t.Fatal(err)
+			}
+			if restore.needed() != (len(tt.files) > 0) {
+				t.Errorf("needed() before the migration = %v, want %v", restore.needed(), len(tt.files) > 0)
+			}
+
+			// The migration rewrites the config, or creates it.
+			for name := range tt.files {
+				os.Remove(filepath.Join(dir, name))
+			}
+			writeFiles(t, dir, map[string]string{tt.config: `{"migrated": true}`})
+			if !restore.needed() {
+				t.Fatal("needed() = false after the migration")
+			}
+
+			out := &printer{w: io.Discard}
+			if err := restoreBackups(restore.configPath, restore.existed, restore.entries, out); err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range tt.wantOld {
+				if got, _ := os.ReadFile(filepath.Join(dir, name)); string(got) != want {
+					t.Errorf("%s = %s, want %s", name, got, want)
+				}
+			}
+			for _, name := range tt.removed {
+				if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+					t.Errorf("%s is still there", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// diagnosticCounts is the number of diagnostics biome check reported for a
+// location after migration.
+type diagnosticCounts struct {
+	errors   int
+	warnings int
+}
+
+func (d diagnosticCounts) total() int {
+	return d.errors + d.warnings
+}
+
+var diagnosticSummary = regexp.MustCompile(`Found (\d+) (error|warning)s?`)
+
+// countDiagnostics runs biome check over dir and counts the errors and
+// warnings it reports. biome check exits non-zero whenever it finds errors,
+// so the exit status only matters when no summary was printed.
+func countDiagnostics(ctx context.Context, dir string, opts *Options, out *printer) (diagnosticCounts, error) {
+	var counts diagnosticCounts
+	cmd, done, err := biomeCommand(ctx, dir, opts, "check", "--max-diagnostics=0", ".")
+	if err != nil {
+		return counts, err
+	}
+	out.verbosef("  $ %s\n", strings.Join(cmd.Args, " "))
+	output, err := cmd.CombinedOutput()
+	runErr := done(err)
+
+	matches := diagnosticSummary.FindAllSubmatch(output, -1)
+	for _, m := range matches {
+		n, _ := strconv.Atoi(string(m[1]))
+		if string(m[2]) == "error" {
+			counts.errors = n
+		} else {
+			counts.warnings = n
+		}
+	}
+
+	if len(matches) == 0 && runErr != nil {
+		return counts, fmt.Errorf("biome check: %w\n%s", runErr, output)
+	}
+	return counts, nil
+}
+
+// printDiagnostics prints the per-location diagnostic counts, worst first.
+func printDiagnostics(out *printer, results []*locationResult) {
+	var checked []*locationResult
+	for _, res := range results {
+		if res.diagnostics != nil {
+			checked = append(checked, res)
+		}
+	}
+	if len(checked) == 0 {
+		return
+	}
+
+	slices.SortStableFunc(checked, func(a, b *locationResult) int {
+		return b.diagnostics.total() - a.diagnostics.total()
+	})
+
+	out.infof("\nBiome diagnostics per location:\n")
+	for _, res := range checked {
+		out.infof("  %5d errors %5d warnings  %s\n", res.diagnostics.errors, res.diagnostics.warnings, res.loc.displayDir())
+	}
+}
+
+// configRejected matches what Biome prints when it can't load biome.json,
+// as opposed to diagnostics about the checked files.
+var configRejected = regexp.MustCompile(`(?i)configuration resulted in errors|failed to (?:parse|deserialize) the configuration`)
+
+// errConfigRejected is recorded when Biome refuses a written biome.json.
+var errConfigRejected = errors.New("biome rejected the configuration")
+
+// validateConfig has Biome load the biome.json in dir by running biome check
+// over it. Lint and format findings don't matter here, only whether Biome
+// accepts the configuration; its output is returned when it doesn't.
+func validateConfig(ctx context.Context, dir string, opts *Options, out *printer) (string, error) {
+	output, err := runBiomeCapture(ctx, dir, opts, out, "check", "--no-errors-on-unmatched", "--max-diagnostics=0", ".")
+	if err != nil && configRejected.Match(output) {
+		return strings.TrimSpace(string(output)), errConfigRejected
+	}
+	if errors.Is(err, errTimedOut) || errors.Is(err, errInterrupted) {
+		return "", err
+	}
+	return "", nil
+}
@@ -0,0 +1,53 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// toolConfigFiles maps each migrated tool to the config file names it is
+// detected by.
+var toolConfigFiles = map[string][]string{
+	"eslint":    eslintConfigFiles,
+	"prettier":  prettierConfigFiles,
+	"stylelint": stylelintConfigFiles,
+}
+
+// toolFiles returns the config files of tool matched at loc. package.json is
+// never included, as it holds more than the embedded config.
+func toolFiles(loc *ConfigLocation, tool string) []string {
+	var files []string
+	for _, path := range loc.Files {
+		if slices.Contains(toolConfigFiles[tool], filepath.Base(path)) {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// keptByCleanup are the tools whose configs -cleanup never deletes. The
+// Stylelint "migration" only enables Biome's CSS linter and carries no rules
+// over, so deleting the config would lose them.
+var keptByCleanup = []string{"stylelint"}
+
+// cleanupConfigs removes the config files of each tool in tools from loc,
+// for -cleanup once their migration succeeded. A symlinked config only loses
+// the link, not the shared file it points to.
+func cleanupConfigs(loc *ConfigLocation, tools []string, out *printer) error {
+	for _, tool := range tools {
+		if slices.Contains(keptByCleanup, tool) {
+			for _, path := range toolFiles(loc, tool) {
+				out.warnf("  Kept: %s, its rules are not carried over to Biome\n", path)
+			}
+			continue
+		}
+		for _, path := range toolFiles(loc, tool) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			out.infof("  Deleted: %s\n", path)
+		}
+	}
+	return nil
+}
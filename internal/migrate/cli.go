@@ -0,0 +1,647 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Flags holds the settings of a command-line run, one field per flag of
+// biome_configurator. The main package fills it from the parsed flags,
+// defaults included, and Run validates it.
+type Flags struct {
+	// Inputs holds each -input value, which may list several directories
+	// separated by commas, and InputList0 the -input-list0 file.
+	Inputs     []string
+	InputList0 string
+
+	DryRun          bool
+	NoDiff          bool
+	ExitOnChanges   bool
+	ValidateSchema  bool
+	DetectOnly      bool
+	Stdout          bool
+	NoMinimalConfig bool
+	ErrorsOnly      bool
+	Verbose         bool
+	Explain         bool
+	LogLevel        string
+	LogFormat       string
+	Format          string
+	Report          string
+	ReportFormat    string
+	RunID           string
+	CheckBiome      bool
+	Interactive     bool
+
+	// SkipDirs and Ignore are comma-separated lists, as on the command
+	// line.
+	SkipDirs      string
+	Ignore        string
+	IncludeHidden bool
+	NoGitignore   bool
+	TrackedOnly   bool
+	SkipSymlinks  bool
+	Aliases       string
+	DirFilter     string
+
+	UseNpx            bool
+	Runner            string
+	RunnerConcurrency int
+	BiomeVersion      string
+	Timeout           time.Duration
+	Concurrency       int
+	KeepGoing         bool
+	MaxFailures       int
+
+	OutputDir            string
+	Monorepo             bool
+	FromPackageRoot      bool
+	OverwriteInvalid     bool
+	LocalSchema          string
+	Emit                 string
+	NoValidate           bool
+	RunCheck             bool
+	ResolveSharedConfigs bool
+	CompareEslint        bool
+	MigrateJSON          bool
+	UpdateGitignore      bool
+	Rollback             bool
+	Force                bool
+	NoManifest           bool
+	Cleanup              bool
+	Backup               bool
+
+	WriteBiomeignore    bool
+	BiomeignoreTemplate string
+	Template            string
+	TemplateVars        []string
+	Overlays            []string
+	JSONPatch           string
+}
+
+// Run runs biome_configurator with the settings in f: it migrates the
+// locations found, or with f.Rollback undoes the last run, printing its
+// progress and problems as it goes. It returns the exit status for the
+// command, 0 on success and 1 when the settings are invalid or any location
+// failed. Once ctx is done the biome commands are killed and no new
+// locations are started.
+func Run(ctx context.Context, f Flags) int {
+	if len(f.Inputs) == 0 && f.InputList0 == "" {
+		fmt.Println("Usage: biome_configurator -input <directory> [-dry-run]")
+		return 1
+	}
+
+	if f.BiomeVersion != "" && !biomeVersionPattern.MatchString(f.BiomeVersion) {
+		fmt.Printf("Invalid -biome-version %q: expected a version such as 1.9.4\n", f.BiomeVersion)
+		return 1
+	}
+
+	if !slices.Contains(emitFormats, f.Emit) {
+		fmt.Printf("Unknown -emit %q, expected %s\n", f.Emit, strings.Join(emitFormats, " or "))
+		return 1
+	}
+
+	if f.Timeout < 0 {
+		fmt.Println("-timeout can't be negative")
+		return 1
+	}
+
+	if f.OutputDir != "" {
+		if f.Cleanup || f.Backup {
+			fmt.Println("-output-dir leaves the input tree untouched and can't be combined with -cleanup or -backup")
+			return 1
+		}
+		abs, err := filepath.Abs(f.OutputDir)
+		if err != nil {
+			fmt.Printf("Error resolving -output-dir: %v\n", err)
+			return 1
+		}
+		f.OutputDir = abs
+	}
+
+	level, ok := logLevels[f.LogLevel]
+	if !ok {
+		fmt.Printf("Unknown -log-level %q, expected debug, info, warn or error\n", f.LogLevel)
+		return 1
+	}
+	if !slices.Contains(logFormats, f.LogFormat) {
+		fmt.Printf("Unknown -log-format %q, expected one of: %s\n", f.LogFormat, strings.Join(logFormats, ", "))
+		return 1
+	}
+	switch {
+	case level <= slog.LevelDebug:
+		f.Verbose = true
+	case level >= slog.LevelWarn:
+		f.ErrorsOnly = true
+	}
+	if f.ErrorsOnly && level < slog.LevelWarn {
+		level = slog.LevelWarn
+	}
+
+	if f.Concurrency < 1 {
+		fmt.Println("-concurrency must be at least 1")
+		return 1
+	}
+	if f.RunnerConcurrency < 1 {
+		fmt.Println("-runner-concurrency must be at least 1")
+		return 1
+	}
+
+	if f.Format != "text" && f.Format != "json" {
+		fmt.Printf("Unknown -format %q, expected text or json\n", f.Format)
+		return 1
+	}
+	jsonOutput := f.Format == "json"
+	if jsonOutput && (f.Stdout || f.Report == "-") {
+		fmt.Println("-format json can't be combined with -stdout or -report -, which also write to stdout")
+		return 1
+	}
+
+	if !slices.Contains(reportFormats, f.ReportFormat) {
+		fmt.Printf("Unknown -report-format %q, expected one of: %s\n", f.ReportFormat, strings.Join(reportFormats, ", "))
+		return 1
+	}
+
+	overlays, err := loadOverlays(f.Overlays)
+	if err != nil {
+		fmt.Printf("Error loading overlay: %v\n", err)
+		return 1
+	}
+
+	var tmpl *configTemplate
+	if f.Template != "" {
+		tmpl, err = loadConfigTemplate(f.Template, f.TemplateVars)
+		if err != nil {
+			fmt.Printf("Error loading template: %v\n", err)
+			return 1
+		}
+	}
+
+	var jsonPatch []jsonPatchOp
+	if f.JSONPatch != "" {
+		jsonPatch, err = loadJSONPatch(f.JSONPatch)
+		if err != nil {
+			fmt.Printf("Error loading JSON patch: %v\n", err)
+			return 1
+		}
+	}
+
+	var ignorePatterns []string
+	if f.BiomeignoreTemplate != "" {
+		_, err := os.Stat(f.BiomeignoreTemplate)
+		if err == nil {
+			ignorePatterns, err = readIgnorePatterns(f.BiomeignoreTemplate)
+		}
+		if err != nil {
+			fmt.Printf("Error reading .biomeignore template: %v\n", err)
+			return 1
+		}
+	}
+
+	if !slices.Contains(runners, f.Runner) {
+		fmt.Printf("Unknown -runner %q, expected one of: %s\n", f.Runner, strings.Join(runners, ", "))
+		return 1
+	}
+	if f.ValidateSchema && !f.DryRun {
+		fmt.Println("-validate requires -dry-run")
+		return 1
+	}
+
+	var localSchema string
+	if f.LocalSchema != "" {
+		localSchema, err = filepath.Abs(f.LocalSchema)
+		if err == nil {
+			_, err = os.Stat(localSchema)
+		}
+		if err != nil {
+			fmt.Printf("Error reading local schema: %v\n", err)
+			return 1
+		}
+	}
+
+	var filter dirFilter
+	if f.DirFilter != "" {
+		filter, err = parseDirFilter(f.DirFilter)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return 1
+		}
+	}
+
+	if f.RunID == "" {
+		f.RunID = newRunID()
+	}
+
+	out := &printer{w: os.Stdout, errorsOnly: f.ErrorsOnly, verbose: f.Verbose, explain: f.Explain, level: level, logFormat: f.LogFormat, runID: f.RunID}
+	if f.Stdout || jsonOutput {
+		// stdout carries only the resulting config or report.
+		out.w = os.Stderr
+	}
+	out.log = newLogger(f.LogFormat, level, out.w, f.RunID)
+	var confirm *confirmer
+	if f.Interactive {
+		confirm = newConfirmer()
+		if confirm == nil {
+			out.warnf("Warning: -interactive ignored, stdin is not a terminal\n")
+		} else if f.Concurrency > 1 {
+			// Prompts need the terminal to themselves.
+			f.Concurrency = 1
+		}
+	}
+	// A progress bar would garble JSON output, even on stderr.
+	if !f.ErrorsOnly && confirm == nil && !jsonOutput && f.LogFormat != logFormatJSON {
+		out.progress = newProgress()
+	}
+	opts := &Options{
+		DryRun:          f.DryRun,
+		DryRunDiff:      f.DryRun && !f.NoDiff,
+		NoMinimalConfig: f.NoMinimalConfig,
+		FromPackageRoot: f.FromPackageRoot,
+		MigrateJSON:     f.MigrateJSON,
+		Overlays:        overlays,
+		template:        tmpl,
+		jsonPatch:       jsonPatch,
+		BiomeVersion:    f.BiomeVersion,
+		LocalSchema:     localSchema,
+		RunCheck:        f.RunCheck,
+		Validate:        !f.NoValidate,
+		CompareEslint:   f.CompareEslint,
+		Backup:          f.Backup,
+		backupStamp:     time.Now().Format(backupStampLayout),
+		Cleanup:         f.Cleanup,
+		Runner:          f.Runner,
+		runnerLimits:    newRunnerLimits(f.RunnerConcurrency),
+		Emit:            f.Emit,
+		Timeout:         f.Timeout,
+		Monorepo:        f.Monorepo,
+		OutputDir:       f.OutputDir,
+		UseNpx:          f.UseNpx,
+		Force:           f.Force,
+		recordChanges:   !f.NoManifest && !f.DryRun && f.OutputDir == "",
+		confirm:         confirm,
+
+		validateSchema: f.ValidateSchema,
+		schemas:        &schemaCache{},
+
+		ResolveSharedConfigs: f.ResolveSharedConfigs,
+		OverwriteInvalid:     f.OverwriteInvalid,
+
+		WriteBiomeignore:    f.WriteBiomeignore,
+		BiomeignoreTemplate: ignorePatterns,
+	}
+
+	roots, overlapping, err := inputRoots(f.Inputs, f.InputList0)
+	if err != nil {
+		out.errorf("Error resolving input directory: %v\n", err)
+		return 1
+	}
+	for _, root := range overlapping {
+		out.warnf("Warning: skipping input %s, it is already covered by another input directory\n", root)
+	}
+	opts.outputPerRoot = len(roots) > 1
+
+	if f.Rollback {
+		if err := rollback(roots, f.Force, f.DryRun, out); err != nil {
+			out.errorf("Rollback failed: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	out.infof("Run ID: %s\n", f.RunID)
+	if !f.DetectOnly {
+		out.infof("Biome command: %s\n", biomeCommandLine(opts))
+	}
+
+	if f.CheckBiome {
+		if where, ok := findInstalledBiome(roots[0]); ok {
+			out.infof("Biome found: %s\n", where)
+		} else {
+			out.warnf("Warning: Biome is not installed or cached; the first migration will download @biomejs/biome through npx, which can take a minute on a slow connection\n")
+		}
+	}
+
+	locations := make(map[string]*ConfigLocation)
+	scan := scanOptions{
+		skipDirs:      splitList(f.SkipDirs),
+		skipSymlinks:  f.SkipSymlinks,
+		includeHidden: f.IncludeHidden,
+		gitignore:     !f.NoGitignore,
+		ignore:        splitList(f.Ignore),
+	}
+	stopSpinner := out.progress.spin("Scanning " + strings.Join(roots, ", "))
+	for _, root := range roots {
+		rootScan := scan
+		if f.TrackedOnly {
+			tracked, err := gitTrackedFiles(root)
+			if err != nil {
+				out.warnf("Warning: -tracked-only ignored, %s is not in a git repository: %v\n", root, err)
+			} else {
+				rootScan.tracked = tracked
+			}
+		}
+
+		var found map[string]*ConfigLocation
+		found, err = findConfigs(root, rootScan)
+		if err != nil {
+			break
+		}
+		for dir, loc := range found {
+			if locations[dir] == nil {
+				locations[dir] = loc
+			}
+		}
+	}
+	if err == nil && f.Aliases != "" {
+		var aliases []pathAlias
+		aliases, err = loadAliases(f.Aliases, roots[0])
+		if err == nil {
+			err = findAliasedConfigs(locations, aliases, roots[0], scan)
+		}
+	}
+	stopSpinner()
+	if err != nil {
+		out.errorf("Error scanning directory: %v\n", err)
+		return 1
+	}
+
+	if filter != nil {
+		for dir, loc := range locations {
+			if !filter(locationAttrs(loc)) {
+				out.verbosef("Skipping %s: doesn't match -dir-filter\n", loc.displayDir())
+				delete(locations, dir)
+			}
+		}
+	}
+
+	if len(locations) == 0 {
+		out.infof("No ESLint, Prettier or Stylelint config files found\n")
+		if jsonOutput {
+			writeJSONReport(os.Stdout, f.RunID, nil)
+		}
+		return 0
+	}
+
+	if (!f.DryRun || !f.NoDiff) && !f.DetectOnly {
+		stopSpinner := out.progress.spin("Checking Biome")
+		var locs []*ConfigLocation
+		for _, dir := range sortedDirs(locations) {
+			locs = append(locs, locations[dir])
+		}
+		err := preflightRunners(ctx, locs, opts, out)
+		stopSpinner()
+		if err != nil {
+			out.errorf("Error: Biome can't be run: %v\n", err)
+			return 1
+		}
+	}
+
+	if f.Stdout {
+		loc := locations[roots[0]]
+		if len(roots) > 1 || loc == nil {
+			out.errorf("Error: -stdout needs -input to be a single directory that contains ESLint or Prettier configs\n")
+			return 1
+		}
+		warnPreviewReferences(loc, out)
+		data, _, err := previewConfig(ctx, loc, opts, out)
+		if err != nil {
+			out.errorf("Error: %v\n", err)
+			return 1
+		}
+		os.Stdout.Write(data)
+		fmt.Println()
+		return 0
+	}
+
+	dirs := sortedDirs(locations)
+
+	if len(roots) > 1 {
+		out.infof("Found configs in %d location(s) across %d input directories", len(locations), len(roots))
+	} else {
+		out.infof("Found configs in %d location(s)", len(locations))
+	}
+	// Structured logs carry each detection as a debug record.
+	for _, dir := range dirs {
+		loc := locations[dir]
+		out.logAttrs(slog.LevelDebug, "detected configs", "dir", loc.displayDir(), "tools", loc.tools(), "files", baseNames(loc.Files))
+	}
+	// A real run prints a line per location as it goes, so the list is
+	// only shown up front with -v.
+	if out.log != nil {
+		out.infof("\n")
+	} else if f.DryRun || f.DetectOnly || out.verbose {
+		out.infof(":\n")
+		for _, dir := range dirs {
+			out.infof("  - %s [%s]\n", locations[dir].displayDir(), strings.Join(locations[dir].tools(), ", "))
+			out.explainf("detected from config file(s) %s\n", strings.Join(baseNames(locations[dir].Files), ", "))
+			for _, link := range locations[dir].Symlinks {
+				target, _ := os.Readlink(link)
+				out.infof("      %s is a symlink to %s\n", filepath.Base(link), target)
+			}
+		}
+	} else {
+		out.infof("\n")
+	}
+
+	if f.DetectOnly {
+		printByTool(out, locations, dirs)
+		if jsonOutput {
+			var detected []*locationResult
+			for _, dir := range dirs {
+				detected = append(detected, &locationResult{loc: locations[dir]})
+			}
+			writeJSONReport(os.Stdout, f.RunID, detected)
+		}
+		return 0
+	}
+
+	failureLimit := f.MaxFailures
+	if !f.KeepGoing {
+		failureLimit = 1
+	}
+	migrate := migrateAll
+	if opts.Monorepo {
+		migrate = migrateMonorepo
+	}
+	results := migrate(ctx, locations, dirs, opts, out, f.Concurrency, failureLimit)
+	aborted := false
+	if len(results) < len(dirs) && ctx.Err() != nil {
+		out.errorf("\nInterrupted; %d location(s) were not processed\n", len(dirs)-len(results))
+		aborted = true
+	} else if len(results) < len(dirs) {
+		failures := 0
+		for _, res := range results {
+			if res.failed() {
+				failures++
+			}
+		}
+		out.errorf("\nAborting after %d failure(s); %d location(s) were not processed\n", failures, len(dirs)-len(results))
+		aborted = true
+	}
+
+	if opts.Backup && !f.DryRun {
+		offerRestore(out, results)
+	}
+
+	if opts.recordChanges {
+		if err := writeRollbackManifests(f.RunID, results); err != nil {
+			out.errorf("Error writing %s: %v\n", RollbackManifestName, err)
+		}
+	}
+
+	var noConfig, reparseFailed, timedOut []string
+	migrated, failed, skipped := 0, 0, 0
+	for _, res := range results {
+		if res.noConfig {
+			noConfig = append(noConfig, res.loc.Dir)
+		}
+		if res.reparseFailed {
+			reparseFailed = append(reparseFailed, res.loc.Dir)
+		}
+		if res.timedOut {
+			timedOut = append(timedOut, res.loc.Dir)
+		}
+		if res.failed() {
+			failed++
+		} else if res.skipped {
+			skipped++
+		} else if res.migrated {
+			migrated++
+		}
+	}
+
+	if len(noConfig) > 0 {
+		out.errorf("\nNo biome.json was produced in %d location(s):\n", len(noConfig))
+		for _, dir := range noConfig {
+			out.errorf("  - %s\n", dir)
+		}
+	}
+
+	if len(timedOut) > 0 {
+		out.errorf("\nBiome timed out after %s in %d location(s):\n", f.Timeout, len(timedOut))
+		for _, dir := range timedOut {
+			out.errorf("  - %s\n", dir)
+		}
+	}
+
+	printDiagnostics(out, results)
+
+	if len(reparseFailed) > 0 {
+		out.errorf("\n%d biome.json file(s) failed to reparse after writing:\n", len(reparseFailed))
+		for _, dir := range reparseFailed {
+			out.errorf("  - %s\n", dir)
+		}
+	}
+
+	if f.Report != "" {
+		if err := writeReport(f.Report, f.ReportFormat, f.RunID, results); err != nil {
+			out.errorf("Error writing report: %v\n", err)
+		}
+	}
+	if jsonOutput {
+		if err := writeJSONReport(os.Stdout, f.RunID, results); err != nil {
+			out.errorf("Error writing JSON output: %v\n", err)
+		}
+	}
+
+	printStats(out, collectStats(locations, results, f.DryRun), f.DryRun, configFileName(opts))
+	if opts.OutputDir != "" && !f.DryRun {
+		printOutputDir(out, opts.OutputDir, results)
+	}
+
+	if f.BiomeVersion != "" {
+		out.infof("\nBiome version pinned to %s\n", f.BiomeVersion)
+	}
+
+	// -errors-only keeps the one-line summary, dry run or not.
+	outcome := fmt.Sprintf("%d location(s) migrated", migrated)
+	if f.DryRun {
+		outcome = fmt.Sprintf("%d location(s) would be migrated", len(results)-failed-skipped)
+	}
+	summary := fmt.Sprintf("%s, %d failed\n", outcome, failed)
+	if confirm != nil || skipped > 0 {
+		summary = fmt.Sprintf("%s, %d failed, %d skipped\n", outcome, failed, skipped)
+	}
+	switch {
+	case f.ErrorsOnly:
+		out.emit(slog.LevelInfo, "%s", summary)
+	case !f.DryRun:
+		out.infof("\n%s", summary)
+	}
+
+	if f.DryRun && (confirm != nil || skipped > 0) {
+		out.infof("\n%d location(s) selected, %d skipped\n", len(results)-failed-skipped, skipped)
+	}
+
+	if aborted {
+		return 1
+	}
+
+	if f.ValidateSchema {
+		var invalid []string
+		for _, res := range results {
+			if len(res.schemaViolations) > 0 {
+				invalid = append(invalid, res.loc.Dir)
+			}
+		}
+		if len(invalid) > 0 {
+			out.errorf("\n%d proposed biome.json file(s) don't match the Biome schema:\n", len(invalid))
+			for _, dir := range invalid {
+				out.errorf("  - %s\n", dir)
+			}
+			return 1
+		}
+	}
+
+	if f.DryRun && f.ExitOnChanges {
+		var outOfDate []string
+		for _, res := range results {
+			if res.outOfDate {
+				outOfDate = append(outOfDate, res.loc.Dir)
+			}
+		}
+		if len(outOfDate) > 0 {
+			out.errorf("\n%d location(s) are not up to date:\n", len(outOfDate))
+			for _, dir := range outOfDate {
+				out.errorf("  - %s\n", dir)
+			}
+			return 1
+		}
+	}
+
+	if failed > 0 {
+		return 1
+	}
+
+	if f.UpdateGitignore && !f.DryRun {
+		path, added, err := addToGlobalGitignore(configFileName(opts))
+		switch {
+		case errors.Is(err, errGitNotFound):
+			out.warnf("\nWarning: -update-gitignore skipped, git is not installed\n")
+		case err != nil:
+			out.warnf("\nWarning: could not update the global gitignore: %v\n", err)
+		case added:
+			out.infof("\nAdded '%s' to %s\n", configFileName(opts), path)
+			return 0
+		default:
+			out.infof("\n'%s' is already in %s\n", configFileName(opts), path)
+			return 0
+		}
+	}
+
+	if f.ErrorsOnly || jsonOutput {
+		return 0
+	}
+
+	out.infof("\nDone! Make sure '%s' is in your global gitignore:\n", configFileName(opts))
+	out.infof("  echo '%s' >> ~/.gitignore_global\n", configFileName(opts))
+	out.infof("  git config --global core.excludesfile ~/.gitignore_global\n")
+	return 0
+}
@@ -0,0 +1,283 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// eslintComparison is the outcome of -compare-eslint for one location.
+type eslintComparison struct {
+	// active is the number of ESLint rules that were switched on.
+	active int
+	// missing lists the active ESLint rules whose Biome name wasn't found
+	// in the generated biome.json.
+	missing []string
+	// unparsed lists the ESLint config files that couldn't be read, so the
+	// comparison only covers the others.
+	unparsed []string
+}
+
+// compareEslintRules compares the active rules of loc's ESLint configs with
+// the rules in biome.json. Rule names are matched through eslintRenames, or
+// else by converting the ESLint name, without its plugin prefix, to
+// camelCase (no-debugger becomes noDebugger). A renamed rule missing from
+// the table counts as not found, so the result is an upper bound on what
+// didn't carry over.
+func compareEslintRules(loc *ConfigLocation, biomeConfigPath string) (*eslintComparison, error) {
+	biomeRules, err := biomeRuleNames(biomeConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cmp := &eslintComparison{}
+	active := make(map[string]bool)
+	for _, path := range loc.Files {
+		if name := filepath.Base(path); name != "package.json" && !slices.Contains(eslintConfigFiles, name) {
+			continue
+		}
+		rules, err := eslintActiveRules(path)
+		if err != nil {
+			cmp.unparsed = append(cmp.unparsed, path)
+			continue
+		}
+		for _, rule := range rules {
+			active[rule] = true
+		}
+	}
+
+	for rule := range active {
+		cmp.active++
+		if !biomeRules[eslintToBiomeName(rule)] {
+			cmp.missing = append(cmp.missing, rule)
+		}
+	}
+	slices.Sort(cmp.missing)
+	return cmp, nil
+}
+
+// eslintActiveRules returns the rules switched on in a JSON or YAML ESLint
+// config, including those in overrides. JavaScript configs aren't readable.
+func eslintActiveRules(path string) ([]string, error) {
+	config, err := readEslintConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []string
+	collect := func(section map[string]any) {
+		set, _ := section["rules"].(map[string]any)
+		for name, setting := range set {
+			if eslintRuleActive(setting) {
+				rules = append(rules, name)
+			}
+		}
+	}
+	collect(config)
+	overrides, _ := config["overrides"].([]any)
+	for _, o := range overrides {
+		if override, ok := o.(map[string]any); ok {
+			collect(override)
+		}
+	}
+	return rules, nil
+}
+
+// eslintRuleActive reports whether an ESLint rule setting ("off", 0,
+// ["error", {...}], ...) turns the rule on.
+func eslintRuleActive(setting any) bool {
+	if list, ok := setting.([]any); ok {
+		if len(list) == 0 {
+			return false
+		}
+		setting = list[0]
+	}
+	switch s := setting.(type) {
+	case string:
+		return s != "off"
+	case float64:
+		return s != 0
+	}
+	return false
+}
+
+// biomeRuleNames collects the rule names configured anywhere in a
+// biome.json: linter.rules.<group>.<rule>, also inside overrides.
+func biomeRuleNames(path string) (map[string]bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config map[string]any
+	if err := decodeConfig(data, isJSONC(path), &config); err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	collect := func(section map[string]any) {
+		linter, _ := section["linter"].(map[string]any)
+		rules, _ := linter["rules"].(map[string]any)
+		for _, group := range rules {
+			if groupRules, ok := group.(map[string]any); ok {
+				for name := range groupRules {
+					names[name] = true
+				}
+			}
+		}
+	}
+	collect(config)
+	if overrides, ok := config["overrides"].([]any); ok {
+		for _, o := range overrides {
+			if override, ok := o.(map[string]any); ok {
+				collect(override)
+			}
+		}
+	}
+	return names, nil
+}
+
+// eslintRenames maps ESLint rule names, without their plugin prefix, to the
+// Biome rules that implement them under another name than the camelCase
+// one.
+var eslintRenames = map[string]string{
+	"ban-types":                      "noBannedTypes",
+	"consistent-type-imports":        "useImportType",
+	"constructor-super":              "noUnreachableSuper",
+	"curly":                          "useBlockStatements",
+	"default-case-last":              "useDefaultSwitchClauseLast",
+	"default-param-last":             "useDefaultParameterLast",
+	"dot-notation":                   "useLiteralKeys",
+	"eqeqeq":                         "noDoubleEquals",
+	"for-direction":                  "useValidForDirection",
+	"getter-return":                  "useGetterReturn",
+	"no-case-declarations":           "noSwitchDeclarations",
+	"no-cond-assign":                 "noAssignInExpressions",
+	"no-control-regex":               "noControlCharactersInRegex",
+	"no-dupe-args":                   "noDuplicateParameters",
+	"no-dupe-class-members":          "noDuplicateClassMembers",
+	"no-dupe-keys":                   "noDuplicateObjectKeys",
+	"no-else-return":                 "noUselessElse",
+	"no-empty":                       "noEmptyBlockStatements",
+	"no-empty-character-class":       "noEmptyCharacterClassInRegex",
+	"no-extra-label":                 "noUselessLabel",
+	"no-fallthrough":                 "noFallthroughSwitchClause",
+	"no-lone-blocks":                 "noUselessLoneBlockStatements",
+	"no-lonely-if":                   "useCollapsedElseIf",
+	"no-loss-of-precision":           "noPrecisionLoss",
+	"no-negated-condition":           "noNegationElse",
+	"no-obj-calls":                   "noGlobalObjectCalls",
+	"no-param-reassign":              "noParameterAssign",
+	"no-plusplus":                    "noPlusPlus",
+	"no-sequences":                   "noCommaOperator",
+	"no-sparse-arrays":               "noSparseArray",
+	"no-this-before-super":           "noUnreachableSuper",
+	"no-undef":                       "noUndeclaredVariables",
+	"no-unused-vars":                 "noUnusedVariables",
+	"no-use-before-define":           "noInvalidUseBeforeDeclaration",
+	"operator-assignment":            "useShorthandAssign",
+	"prefer-arrow-callback":          "useArrowFunction",
+	"prefer-as-const":                "useAsConstAssertion",
+	"prefer-const":                   "useConst",
+	"prefer-exponentiation-operator": "useExponentiationOperator",
+	"prefer-numeric-literals":        "useNumericLiterals",
+	"prefer-rest-params":             "noArguments",
+	"prefer-template":                "useTemplate",
+	"require-yield":                  "useYield",
+	"use-isnan":                      "useIsNan",
+	"valid-typeof":                   "useValidTypeof",
+
+	// Plugin rules.
+	"alt-text":                "useAltText",
+	"anchor-is-valid":         "useValidAnchor",
+	"exhaustive-deps":         "useExhaustiveDependencies",
+	"jsx-key":                 "useJsxKeyInIterable",
+	"jsx-no-useless-fragment": "noUselessFragments",
+	"no-array-for-each":       "noForEach",
+	"no-danger":               "noDangerouslySetInnerHtml",
+	"rules-of-hooks":          "useHookAtTopLevel",
+}
+
+// eslintToBiomeName converts an ESLint rule name such as
+// @typescript-eslint/no-explicit-any to Biome's naming, noExplicitAny,
+// using eslintRenames for the rules Biome named differently.
+func eslintToBiomeName(rule string) string {
+	if i := strings.LastIndex(rule, "/"); i >= 0 {
+		rule = rule[i+1:]
+	}
+	if name, ok := eslintRenames[rule]; ok {
+		return name
+	}
+	var b strings.Builder
+	upper := false
+	for _, r := range rule {
+		if r == '-' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// stripJSONComments blanks out // and /* */ comments outside of strings, as
+// ESLint allows them in its JSON configs. A block comment becomes a space,
+// so it still separates the tokens around it.
+func stripJSONComments(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString, escaped := false, false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := strings.Index(string(data[i+2:]), "*/")
+			if end < 0 {
+				return out
+			}
+			out = append(out, ' ')
+			i += end + 3
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// printComparison reports the ESLint rules that didn't carry over.
+func printComparison(out *printer, cmp *eslintComparison) {
+	for _, path := range cmp.unparsed {
+		out.warnf("  Warning: couldn't read rules from %s, comparison is partial\n", path)
+	}
+	if cmp.active == 0 {
+		return
+	}
+	out.infof("  ESLint rules whose Biome name was not found: %d of %d\n", len(cmp.missing), cmp.active)
+	for _, rule := range cmp.missing {
+		out.infof("    - %s\n", rule)
+	}
+}
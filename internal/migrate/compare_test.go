@@ -0,0 +1,46 @@
+package migrate
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEslintToBiomeName(t *testing.T) {
+	tests := []struct{ rule, want string }{
+		{"no-debugger", "noDebugger"},
+		{"no-unused-vars", "noUnusedVariables"},
+		{"eqeqeq", "noDoubleEquals"},
+		{"prefer-const", "useConst"},
+		{"no-plusplus", "noPlusPlus"},
+		{"@typescript-eslint/no-explicit-any", "noExplicitAny"},
+		{"@typescript-eslint/no-unused-vars", "noUnusedVariables"},
+		{"react-hooks/exhaustive-deps", "useExhaustiveDependencies"},
+	}
+	for _, tt := range tests {
+		if got := eslintToBiomeName(tt.rule); got != tt.want {
+			t.Errorf("eslintToBiomeName(%q) = %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestStripJSONComments(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{`{"a": 1}`, `{"a": 1}`},
+		{"{\"a\": 1 // note\n}", "{\"a\": 1 \n}"},
+		{`{"a":/* one */1}`, `{"a": 1}`},
+		{`{"a": 1/**/,"b": 2}`, `{"a": 1 ,"b": 2}`},
+		{`{"url": "http://x/*y*/"}`, `{"url": "http://x/*y*/"}`},
+		{`{"a": "\"//"}`, `{"a": "\"//"}`},
+	}
+	for _, tt := range tests {
+		if got := string(stripJSONComments([]byte(tt.in))); got != tt.want {
+			t.Errorf("stripJSONComments(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	// Tokens on both sides of a comment must not run together.
+	var v []any
+	if err := json.Unmarshal(stripJSONComments([]byte(`[1/* */2]`)), &v); err == nil {
+		t.Errorf("[1/* */2] decoded as %v, want an error, not the number 12", v)
+	}
+}
@@ -0,0 +1,142 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// diffOp is one line of an edit script: ' ' kept, '-' removed, '+' added.
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// unifiedDiff returns the changes from a to b in unified diff format, or ""
+// when they are equal. The line-level LCS is quadratic, which is fine for
+// config files.
+func unifiedDiff(oldName, newName string, a, b []byte) string {
+	ops := diffLines(splitLines(string(a)), splitLines(string(b)))
+
+	var hunks strings.Builder
+	for start := 0; start < len(ops); {
+		// Find the next change and the extent of its hunk, merging changes
+		// whose context would overlap.
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		end := first
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				end = i + 1
+			} else if i-end >= 2*diffContext {
+				break
+			}
+		}
+		from := max(first-diffContext, start)
+		to := min(end+diffContext, len(ops))
+
+		oldStart, newStart := lineNumbers(ops[:from])
+		oldLen, newLen := lineCounts(ops[from:to])
+		fmt.Fprintf(&hunks, "@@ -%s +%s @@\n", hunkRange(oldStart, oldLen), hunkRange(newStart, newLen))
+		for _, op := range ops[from:to] {
+			hunks.WriteByte(op.kind)
+			hunks.WriteString(op.line)
+			hunks.WriteByte('\n')
+		}
+		start = to
+	}
+
+	if hunks.Len() == 0 {
+		return ""
+	}
+	return fmt.Sprintf("--- %s\n+++ %s\n%s", oldName, newName, hunks.String())
+}
+
+// diffLines computes an edit script turning a into b from their longest
+// common subsequence.
+func diffLines(a, b []string) []diffOp {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// lineNumbers returns the 1-based old and new line numbers following ops.
+func lineNumbers(ops []diffOp) (oldLine, newLine int) {
+	oldLen, newLen := lineCounts(ops)
+	return oldLen + 1, newLen + 1
+}
+
+// lineCounts returns how many old and new lines ops span.
+func lineCounts(ops []diffOp) (oldLen, newLen int) {
+	for _, op := range ops {
+		if op.kind != '+' {
+			oldLen++
+		}
+		if op.kind != '-' {
+			newLen++
+		}
+	}
+	return oldLen, newLen
+}
+
+// hunkRange formats a hunk header range. An empty range is numbered after
+// the line it follows, as diff(1) does.
+func hunkRange(start, length int) string {
+	if length == 0 {
+		start--
+	}
+	if length == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, length)
+}
+
+// splitLines splits s into lines without their terminators.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,93 @@
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// markDisabledConfigs sets PrettierDisabled and EslintEmpty on loc from the
+// config files it was detected by. A tool only counts as disabled when every
+// one of its configs here is.
+func markDisabledConfigs(loc *ConfigLocation) {
+	manifest, _ := readPackageJSON(loc.Dir)
+	loc.PrettierDisabled = loc.HasPrettier && allDisabled(loc, prettierConfigFiles, manifest, "prettier")
+	loc.EslintEmpty = loc.HasEslint && allDisabled(loc, eslintConfigFiles, manifest, "eslintConfig")
+}
+
+// allDisabled reports whether loc's configs among names, and the key in
+// its package.json, are all empty or false. JavaScript configs can't be
+// judged and count as real.
+func allDisabled(loc *ConfigLocation, names []string, manifest map[string]json.RawMessage, key string) bool {
+	found := false
+	for _, path := range loc.Files {
+		name := filepath.Base(path)
+		switch {
+		case name == "package.json":
+			value, ok := manifest[key]
+			if !ok || string(value) == "null" {
+				continue
+			}
+			found = true
+			if !emptyConfig(value) {
+				return false
+			}
+		case slices.Contains(names, name):
+			found = true
+			data, err := os.ReadFile(path)
+			if err != nil || !emptyConfig(data) {
+				return false
+			}
+		}
+	}
+	return found
+}
+
+// emptyConfig reports whether data is a config that configures nothing:
+// blank, false, or an empty object.
+func emptyConfig(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return true
+	}
+	var value any
+	if err := json.Unmarshal(stripJSONComments(data), &value); err != nil {
+		return false
+	}
+	switch value := value.(type) {
+	case bool:
+		return !value
+	case map[string]any:
+		return len(value) == 0
+	}
+	return false
+}
+
+// disabledTools lists the tools detected at loc whose configs are empty or
+// switched off, so their migration is skipped.
+func (loc *ConfigLocation) disabledTools() []string {
+	var tools []string
+	if loc.EslintEmpty {
+		tools = append(tools, "eslint")
+	}
+	if loc.PrettierDisabled {
+		tools = append(tools, "prettier")
+	}
+	return tools
+}
+
+// disabledNotes describes the skipped tools for a location's outcome line,
+// e.g. "prettier: disabled, skipped".
+func (loc *ConfigLocation) disabledNotes() string {
+	var notes []string
+	if loc.EslintEmpty {
+		notes = append(notes, "eslint: empty, skipped")
+	}
+	if loc.PrettierDisabled {
+		notes = append(notes, "prettier: disabled, skipped")
+	}
+	return strings.Join(notes, "; ")
+}
@@ -0,0 +1,97 @@
+package migrate
+
+import "testing"
+
+func TestEmptyConfig(t *testing.T) {
+	tests := []struct {
+		data string
+		want bool
+	}{
+		{"", true},
+		{"  \n", true},
+		{"{}", true},
+		{"{ /* nothing yet */ }", true},
+		{"false", true},
+		{"true", false},
+		{`{"semi": false}`, false},
+		{`"@org/prettier-config"`, false},
+		{"[]", false},
+		{"semi: false", false},
+	}
+	for _, tt := range tests {
+		if got := emptyConfig([]byte(tt.data)); got != tt.want {
+			t.Errorf("emptyConfig(%q) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestMarkDisabledConfigs(t *testing.T) {
+	tests := []struct {
+		name             string
+		files            map[string]string
+		wantPrettierOff  bool
+		wantEslintEmpty  bool
+		wantDisabledNote string
+	}{
+		{
+			name:             "prettier false in package.json",
+			files:            map[string]string{"package.json": `{"prettier": false}`},
+			wantPrettierOff:  true,
+			wantDisabledNote: "prettier: disabled, skipped",
+		},
+		{
+			name:             "empty .eslintrc.json",
+			files:            map[string]string{".eslintrc.json": "{}"},
+			wantEslintEmpty:  true,
+			wantDisabledNote: "eslint: empty, skipped",
+		},
+		{
+			name:             "both empty",
+			files:            map[string]string{".eslintrc.json": "{}", ".prettierrc": ""},
+			wantPrettierOff:  true,
+			wantEslintEmpty:  true,
+			wantDisabledNote: "eslint: empty, skipped; prettier: disabled, skipped",
+		},
+		{
+			name:  "real configs",
+			files: map[string]string{".eslintrc.json": `{"rules": {"no-var": "error"}}`, ".prettierrc": `{"semi": false}`},
+		},
+		{
+			name:  "JavaScript configs count as real",
+			files: map[string]string{"eslint.config.js": "export default [];", ".prettierrc.js": "module.exports = {};"},
+		},
+		{
+			// Every config has to be empty for the tool to count as disabled.
+			name:            "empty .prettierrc next to package.json settings",
+			files:           map[string]string{".prettierrc": "{}", "package.json": `{"prettier": {"semi": false}}`},
+			wantPrettierOff: false,
+		},
+		{
+			name:             "empty eslintConfig next to an empty .eslintrc.json",
+			files:            map[string]string{".eslintrc.json": "{}", "package.json": `{"eslintConfig": {}}`},
+			wantEslintEmpty:  true,
+			wantDisabledNote: "eslint: empty, skipped",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+			locations, err := findConfigs(root, scanOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			loc := locations[root]
+			if loc == nil {
+				t.Fatalf("findConfigs missed %s", root)
+			}
+
+			if loc.PrettierDisabled != tt.wantPrettierOff || loc.EslintEmpty != tt.wantEslintEmpty {
+				t.Errorf("PrettierDisabled, EslintEmpty = %v, %v, want %v, %v", loc.PrettierDisabled, loc.EslintEmpty, tt.wantPrettierOff, tt.wantEslintEmpty)
+			}
+			if got := loc.disabledNotes(); got != tt.wantDisabledNote {
+				t.Errorf("disabledNotes = %q, want %q", got, tt.wantDisabledNote)
+			}
+		})
+	}
+}
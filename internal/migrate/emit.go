@@ -0,0 +1,119 @@
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Output formats accepted by -emit.
+const (
+	emitJSON  = "json"
+	emitJSONC = "jsonc"
+)
+
+var emitFormats = []string{emitJSON, emitJSONC}
+
+// jsoncHeader starts every biome.jsonc the tool writes.
+const jsoncHeader = `// Generated by biome_configurator from this directory's ESLint/Prettier configs.
+// Rerun the tool instead of editing by hand, and keep this file in your global gitignore.
+`
+
+// configFileName returns the name of the Biome config written to each
+// location: biome.json, or biome.jsonc with -emit jsonc.
+func configFileName(opts *Options) string {
+	if opts.Emit == emitJSONC {
+		return "biome.jsonc"
+	}
+	return "biome.json"
+}
+
+// existingConfigPath returns the Biome config a run in dir starts from. With
+// -emit jsonc an existing biome.json is used when there is no biome.jsonc
+// yet, since it gets renamed rather than left next to the new file.
+func existingConfigPath(dir string, opts *Options) string {
+	path := filepath.Join(dir, configFileName(opts))
+	if opts.Emit != emitJSONC {
+		return path
+	}
+	if _, err := os.Stat(path); err != nil {
+		legacy := filepath.Join(dir, "biome.json")
+		if _, err := os.Stat(legacy); err == nil {
+			return legacy
+		}
+	}
+	return path
+}
+
+// encodeConfig serializes a config for the -emit format. Both are indented
+// with two spaces and have no trailing newline; jsonc adds the generated-file
+// header and a trailing comma after the last entry of every object and
+// array, so appending an entry by hand only touches one line.
+func encodeConfig(config map[string]any, opts *Options) ([]byte, error) {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil || opts.Emit != emitJSONC {
+		return data, err
+	}
+	return append([]byte(jsoncHeader), addTrailingCommas(data)...), nil
+}
+
+// decodeConfig parses a Biome config into v. With jsonc, comments and
+// trailing commas are allowed, as Biome allows them in biome.jsonc.
+func decodeConfig(data []byte, jsonc bool, v any) error {
+	if jsonc {
+		data = stripTrailingCommas(stripJSONComments(data))
+	}
+	return json.Unmarshal(data, v)
+}
+
+// isJSONC reports whether path names a JSON-with-comments config.
+func isJSONC(path string) bool {
+	return filepath.Ext(path) == ".jsonc"
+}
+
+// addTrailingCommas adds a comma to every line of indented JSON that ends an
+// entry right before a closing bracket. MarshalIndent never puts a newline
+// inside a string, so working line by line is safe.
+func addTrailingCommas(data []byte) []byte {
+	lines := bytes.Split(data, []byte("\n"))
+	for i := 0; i+1 < len(lines); i++ {
+		next := bytes.TrimLeft(lines[i+1], " ")
+		if len(next) == 0 || len(lines[i]) == 0 || (next[0] != '}' && next[0] != ']') {
+			continue
+		}
+		if last := lines[i][len(lines[i])-1]; last != '{' && last != '[' && last != ',' {
+			lines[i] = append(lines[i], ',')
+		}
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
+
+// stripTrailingCommas removes commas that directly precede a closing bracket,
+// outside of strings. Comments must already be stripped.
+func stripTrailingCommas(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString, escaped := false, false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == ',':
+			rest := bytes.TrimLeft(data[i+1:], " \t\r\n")
+			if len(rest) > 0 && (rest[0] == '}' || rest[0] == ']') {
+				continue
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
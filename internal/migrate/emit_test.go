@@ -0,0 +1,173 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncodeConfig(t *testing.T) {
+	config := map[string]any{
+		"files":     map[string]any{"ignore": []any{"dist/**", "coverage/**"}},
+		"formatter": map[string]any{"indentStyle": "space", "lineWidth": 100.0},
+		"overrides": []any{},
+	}
+
+	tests := []struct {
+		emit string
+		want string
+	}{
+		{
+			emit: emitJSON,
+			want: `{
+  "files": {
+    "ignore": [
+      "dist/**",
+      "coverage/**"
+    ]
+  },
+  "formatter": {
+    "indentStyle": "space",
+    "lineWidth": 100
+  },
+  "overrides": []
+}`,
+		},
+		{
+			emit: emitJSONC,
+			want: jsoncHeader + `{
+  "files": {
+    "ignore": [
+      "dist/**",
+      "coverage/**",
+    ],
+  },
+  "formatter": {
+    "indentStyle": "space",
+    "lineWidth": 100,
+  },
+  "overrides": [],
+}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.emit, func(t *testing.T) {
+			opts := &Options{Emit: tt.emit}
+			data, err := encodeConfig(config, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("encodeConfig =\n%s\nwant\n%s", data, tt.want)
+			}
+
+			var decoded map[string]any
+			if err := decodeConfig(data, tt.emit == emitJSONC, &decoded); err != nil {
+				t.Fatalf("decodeConfig: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, config) {
+				t.Errorf("decodeConfig = %v, want %v", decoded, config)
+			}
+		})
+	}
+}
+
+func TestStripTrailingCommas(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"object", `{"a": 1,}`, `{"a": 1}`},
+		{"array across lines", "[\n  1,\n  2,\n]", "[\n  1,\n  2\n]"},
+		{"nested", `{"a": [1, {"b": 2,},],}`, `{"a": [1, {"b": 2}]}`},
+		{"comma inside a string", `{"a": ",}", "b": "\",]",}`, `{"a": ",}", "b": "\",]"}`},
+		{"no trailing commas", `{"a": [1, 2]}`, `{"a": [1, 2]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(stripTrailingCommas([]byte(tt.input))); got != tt.want {
+				t.Errorf("stripTrailingCommas(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExistingConfigPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		emit  string
+		files []string
+		want  string
+	}{
+		{name: "json", emit: emitJSON, files: []string{"biome.json"}, want: "biome.json"},
+		{name: "json ignores biome.jsonc", emit: emitJSON, files: []string{"biome.jsonc"}, want: "biome.json"},
+		{name: "jsonc without configs", emit: emitJSONC, want: "biome.jsonc"},
+		{name: "jsonc renames biome.json", emit: emitJSONC, files: []string{"biome.json"}, want: "biome.json"},
+		{name: "jsonc prefers biome.jsonc", emit: emitJSONC, files: []string{"biome.json", "biome.jsonc"}, want: "biome.jsonc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := make(map[string]string)
+			for _, name := range tt.files {
+				files[name] = "{}"
+			}
+			writeFiles(t, dir, files)
+
+			if got := existingConfigPath(dir, &Options{Emit: tt.emit}); got != filepath.Join(dir, tt.want) {
+				t.Errorf("existingConfigPath = %s, want %s", got, filepath.Join(dir, tt.want))
+			}
+		})
+	}
+}
+
+func TestMigrateEmitJSONC(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+	}{
+		{name: "new config"},
+		{name: "existing biome.json", existing: `{"formatter": {"lineWidth": 120}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// An empty ESLint config is skipped, so no biome command runs.
+			root := t.TempDir()
+			files := map[string]string{".eslintrc.json": "{}"}
+			if tt.existing != "" {
+				files["biome.json"] = tt.existing
+			}
+			writeFiles(t, root, files)
+			locations, err := FindConfigs(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := Migrate(locations[root], Options{Emit: emitJSONC}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(filepath.Join(root, "biome.json")); err == nil {
+				t.Error("biome.json was left next to biome.jsonc")
+			}
+			data, err := os.ReadFile(filepath.Join(root, "biome.jsonc"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(string(data), jsoncHeader) {
+				t.Errorf("biome.jsonc doesn't start with the generated-file header:\n%s", data)
+			}
+			if !strings.Contains(string(data), ",\n}") {
+				t.Errorf("biome.jsonc has no trailing commas:\n%s", data)
+			}
+
+			var config map[string]any
+			if err := decodeConfig(data, true, &config); err != nil {
+				t.Fatalf("biome.jsonc doesn't parse: %v", err)
+			}
+			if formatter, _ := config["formatter"].(map[string]any); tt.existing != "" && formatter["lineWidth"] != 120.0 {
+				t.Errorf("formatter = %v, want the existing lineWidth 120", config["formatter"])
+			}
+		})
+	}
+}
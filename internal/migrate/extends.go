@@ -0,0 +1,157 @@
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// errESLintConfigFormat is returned for ESLint configs that are code rather
+// than data and can't be read without running Node.
+var errESLintConfigFormat = errors.New("JavaScript configs can't be read")
+
+// readEslintConfig decodes a JSON or YAML ESLint config. Legacy .eslintrc
+// files may hold either, so JSON is tried first. For package.json the
+// "eslintConfig" key is returned.
+func readEslintConfig(path string) (map[string]any, error) {
+	switch filepath.Ext(path) {
+	case ".js", ".cjs", ".mjs":
+		return nil, fmt.Errorf("%s: %w", path, errESLintConfigFormat)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if filepath.Base(path) == "package.json" {
+		var manifest struct {
+			EslintConfig map[string]any `json:"eslintConfig"`
+		}
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return manifest.EslintConfig, nil
+	}
+
+	var config map[string]any
+	jsonErr := json.Unmarshal(stripJSONComments(data), &config)
+	if jsonErr == nil {
+		return config, nil
+	}
+	if ext := filepath.Ext(path); ext == ".json" {
+		return nil, fmt.Errorf("%s: %w", path, jsonErr)
+	}
+
+	value, err := parseYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	config, ok := value.(map[string]any)
+	if !ok && value != nil {
+		return nil, fmt.Errorf("%s: expected a mapping at the top level", path)
+	}
+	return config, nil
+}
+
+// eslintExtends returns the "extends" entries of an ESLint config, which may
+// be a single string or a list.
+func eslintExtends(config map[string]any) []string {
+	switch ext := config["extends"].(type) {
+	case string:
+		return []string{ext}
+	case []any:
+		var refs []string
+		for _, e := range ext {
+			if ref, ok := e.(string); ok {
+				refs = append(refs, ref)
+			}
+		}
+		return refs
+	}
+	return nil
+}
+
+// hasLocalRules reports whether an ESLint config sets any rules of its own,
+// at the top level or in an override.
+func hasLocalRules(config map[string]any) bool {
+	if rules, ok := config["rules"].(map[string]any); ok && len(rules) > 0 {
+		return true
+	}
+	overrides, _ := config["overrides"].([]any)
+	for _, o := range overrides {
+		override, _ := o.(map[string]any)
+		if rules, ok := override["rules"].(map[string]any); ok && len(rules) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// sharedConfigPackage returns the npm package an "extends" entry loads, using
+// ESLint's naming rules: "airbnb" is eslint-config-airbnb, "@org" is
+// @org/eslint-config and "plugin:react/recommended" comes from
+// eslint-plugin-react. Built-in "eslint:" configs and file paths yield false.
+func sharedConfigPackage(ref string) (string, bool) {
+	if strings.HasPrefix(ref, "eslint:") || strings.HasPrefix(ref, ".") || filepath.IsAbs(ref) {
+		return "", false
+	}
+
+	prefix := "eslint-config"
+	if name, ok := strings.CutPrefix(ref, "plugin:"); ok {
+		prefix = "eslint-plugin"
+		// Drop the config name: plugin:react/recommended is react's.
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			name = name[:i]
+		}
+		ref = name
+	}
+
+	if strings.HasPrefix(ref, "@") {
+		scope, name, _ := strings.Cut(ref, "/")
+		switch {
+		case name == "":
+			return scope + "/" + prefix, true
+		case strings.HasPrefix(name, prefix):
+			return ref, true
+		default:
+			return scope + "/" + prefix + "-" + name, true
+		}
+	}
+	if strings.HasPrefix(ref, prefix) {
+		return ref, true
+	}
+	return prefix + "-" + ref, true
+}
+
+// warnExtendsOnly warns when one of loc's ESLint configs only extends shared
+// configs and sets no rules itself: biome migrate can't follow those
+// packages, so the migrated rules will likely be incomplete.
+func warnExtendsOnly(loc *ConfigLocation, out *printer) {
+	for _, path := range loc.Files {
+		name := filepath.Base(path)
+		if name != "package.json" && !slices.Contains(eslintConfigFiles, name) {
+			continue
+		}
+		config, err := readEslintConfig(path)
+		if err != nil || config == nil || hasLocalRules(config) {
+			continue
+		}
+
+		var packages []string
+		for _, ref := range eslintExtends(config) {
+			if pkg, ok := sharedConfigPackage(ref); ok && !slices.Contains(packages, pkg) {
+				packages = append(packages, pkg)
+			}
+		}
+		if len(packages) == 0 {
+			continue
+		}
+		out.warnf("Warning: %s sets no rules of its own and only extends shared configs, so the migration will likely be incomplete; port these by hand: %s\n",
+			path, strings.Join(packages, ", "))
+	}
+}
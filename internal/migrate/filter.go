@@ -0,0 +1,343 @@
+package migrate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// dirAttrs are the location attributes a -dir-filter expression can test.
+type dirAttrs struct {
+	hasEslint      bool
+	hasPrettier    bool
+	hasStylelint   bool
+	hasPackageJson bool
+	// depth is the number of directories between the input root and the
+	// location; the root itself has depth 0.
+	depth int
+	// relPath is the location's slash-separated path relative to the input
+	// root.
+	relPath string
+}
+
+// locationAttrs computes the filter attributes of loc relative to the input
+// root it was found under. Aliased locations are judged by their logical
+// path.
+func locationAttrs(loc *ConfigLocation) dirAttrs {
+	dir := loc.Dir
+	if loc.LogicalDir != "" {
+		dir = loc.LogicalDir
+	}
+	rel, err := filepath.Rel(loc.Root, dir)
+	if err != nil {
+		rel = dir
+	}
+	rel = filepath.ToSlash(rel)
+
+	depth := 0
+	if rel != "." {
+		depth = strings.Count(rel, "/") + 1
+	}
+
+	_, err = os.Stat(filepath.Join(loc.Dir, "package.json"))
+	return dirAttrs{
+		hasEslint:      loc.HasEslint,
+		hasPrettier:    loc.HasPrettier,
+		hasStylelint:   loc.HasStylelint,
+		hasPackageJson: err == nil,
+		depth:          depth,
+		relPath:        rel,
+	}
+}
+
+// dirFilter is a compiled -dir-filter expression.
+type dirFilter func(dirAttrs) bool
+
+// parseDirFilter compiles a -dir-filter expression:
+//
+//	expr    = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | primary
+//	primary = "(" expr ")" | "true" | "false"
+//	        | "hasEslint" | "hasPrettier" | "hasStylelint" | "hasPackageJson"
+//	        | "depth" ( "==" | "!=" | "<" | "<=" | ">" | ">=" ) integer
+//	        | "pathMatches" "(" string ")"
+//
+// Strings are single- or double-quoted. pathMatches globs are matched
+// against the path relative to the input root; * and ? stay within one
+// path segment and ** spans any number of them.
+func parseDirFilter(expr string) (dirFilter, error) {
+	tokens, err := lexDirFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "unexpected %q", tok.text)
+	}
+	return f, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+)
+
+type filterToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func lexDirFilter(expr string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsLetter(c):
+			start := i
+			for i < len(expr) && (unicode.IsLetter(rune(expr[i])) || unicode.IsDigit(rune(expr[i]))) {
+				i++
+			}
+			tokens = append(tokens, filterToken{tokIdent, expr[start:i], start})
+		case unicode.IsDigit(c):
+			start := i
+			for i < len(expr) && unicode.IsDigit(rune(expr[i])) {
+				i++
+			}
+			tokens = append(tokens, filterToken{tokNumber, expr[start:i], start})
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(expr[i+1:], expr[i])
+			if end < 0 {
+				return nil, fmt.Errorf("dir-filter: unterminated string at position %d", i+1)
+			}
+			tokens = append(tokens, filterToken{tokString, expr[i+1 : i+1+end], i})
+			i += end + 2
+		default:
+			op := ""
+			for _, candidate := range []string{"&&", "||", "==", "!=", "<=", ">=", "!", "<", ">", "(", ")"} {
+				if strings.HasPrefix(expr[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("dir-filter: unexpected character %q at position %d", c, i+1)
+			}
+			tokens = append(tokens, filterToken{tokOp, op, i})
+			i += len(op)
+		}
+	}
+	return append(tokens, filterToken{tokEOF, "", len(expr)}), nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *filterParser) errorf(tok filterToken, format string, args ...any) error {
+	if tok.kind == tokEOF {
+		return fmt.Errorf("dir-filter: "+format+" at end of expression", args...)
+	}
+	return fmt.Errorf("dir-filter: "+format+" at position %d", append(args, tok.pos+1)...)
+}
+
+func (p *filterParser) expect(kind tokenKind, text string) (filterToken, error) {
+	tok := p.next()
+	if tok.kind != kind || (text != "" && tok.text != text) {
+		want := text
+		if want == "" {
+			want = map[tokenKind]string{tokString: "a string", tokNumber: "a number"}[kind]
+		} else {
+			want = strconv.Quote(want)
+		}
+		return tok, p.errorf(tok, "expected %s", want)
+	}
+	return tok, nil
+}
+
+func (p *filterParser) parseOr() (dirFilter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().text == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(a dirAttrs) bool { return l(a) || right(a) }
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (dirFilter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().text == "&&" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(a dirAttrs) bool { return l(a) && right(a) }
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (dirFilter, error) {
+	if tok := p.peek(); tok.kind == tokOp && tok.text == "!" {
+		p.next()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(a dirAttrs) bool { return !inner(a) }, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *filterParser) parsePrimary() (dirFilter, error) {
+	tok := p.next()
+	if tok.kind == tokOp && tok.text == "(" {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokOp, ")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+
+	if tok.kind == tokEOF {
+		return nil, p.errorf(tok, "expected an attribute")
+	}
+	if tok.kind != tokIdent {
+		return nil, p.errorf(tok, "expected an attribute, got %q", tok.text)
+	}
+
+	switch tok.text {
+	case "true":
+		return func(dirAttrs) bool { return true }, nil
+	case "false":
+		return func(dirAttrs) bool { return false }, nil
+	case "hasEslint":
+		return func(a dirAttrs) bool { return a.hasEslint }, nil
+	case "hasPrettier":
+		return func(a dirAttrs) bool { return a.hasPrettier }, nil
+	case "hasStylelint":
+		return func(a dirAttrs) bool { return a.hasStylelint }, nil
+	case "hasPackageJson":
+		return func(a dirAttrs) bool { return a.hasPackageJson }, nil
+	case "depth":
+		return p.parseDepth()
+	case "pathMatches":
+		if _, err := p.expect(tokOp, "("); err != nil {
+			return nil, err
+		}
+		glob, err := p.expect(tokString, "")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokOp, ")"); err != nil {
+			return nil, err
+		}
+		re := globRegexp(glob.text)
+		return func(a dirAttrs) bool { return re.MatchString(a.relPath) }, nil
+	}
+	return nil, p.errorf(tok, "unknown attribute %q", tok.text)
+}
+
+func (p *filterParser) parseDepth() (dirFilter, error) {
+	op := p.next()
+	if op.kind != tokOp {
+		return nil, p.errorf(op, "expected a comparison after depth")
+	}
+	numTok, err := p.expect(tokNumber, "")
+	if err != nil {
+		return nil, err
+	}
+	n, _ := strconv.Atoi(numTok.text)
+
+	switch op.text {
+	case "==":
+		return func(a dirAttrs) bool { return a.depth == n }, nil
+	case "!=":
+		return func(a dirAttrs) bool { return a.depth != n }, nil
+	case "<":
+		return func(a dirAttrs) bool { return a.depth < n }, nil
+	case "<=":
+		return func(a dirAttrs) bool { return a.depth <= n }, nil
+	case ">":
+		return func(a dirAttrs) bool { return a.depth > n }, nil
+	case ">=":
+		return func(a dirAttrs) bool { return a.depth >= n }, nil
+	}
+	return nil, p.errorf(op, "expected a comparison after depth")
+}
+
+// globRegexp compiles a slash-separated glob into an anchored regexp. * and
+// ? match within a path segment, ** matches across segments. The glob is
+// read rune by rune, so ? matches one character, not one byte, and
+// non-ASCII names match literally.
+func globRegexp(glob string) *regexp.Regexp {
+	runes := []rune(glob)
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				i++
+				if i+1 < len(runes) && runes[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
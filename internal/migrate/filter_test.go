@@ -0,0 +1,158 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDirFilter(t *testing.T) {
+	eslintOnly := dirAttrs{hasEslint: true, depth: 2, relPath: "packages/web"}
+	prettierPkg := dirAttrs{hasPrettier: true, hasPackageJson: true, depth: 1, relPath: "tools"}
+	root := dirAttrs{hasEslint: true, hasPrettier: true, relPath: "."}
+
+	tests := []struct {
+		expr  string
+		attrs dirAttrs
+		want  bool
+	}{
+		{"hasEslint", eslintOnly, true},
+		{"!hasEslint", eslintOnly, false},
+		{"!!hasEslint", eslintOnly, true},
+		{"true", eslintOnly, true},
+		{"false", eslintOnly, false},
+		{"hasStylelint", eslintOnly, false},
+		{"hasPackageJson", prettierPkg, true},
+
+		// && binds tighter than ||.
+		{"true || false && false", eslintOnly, true},
+		{"false && false || true", eslintOnly, true},
+		{"(true || false) && false", eslintOnly, false},
+		{"hasPrettier || hasEslint && depth > 5", prettierPkg, true},
+		{"(hasPrettier || hasEslint) && depth > 5", prettierPkg, false},
+		// ! applies to the nearest operand only.
+		{"!hasEslint && hasPrettier", prettierPkg, true},
+		{"!(hasEslint || hasPrettier)", prettierPkg, false},
+		{"!false || false", eslintOnly, true},
+		{"((hasEslint))", eslintOnly, true},
+
+		{"depth == 2", eslintOnly, true},
+		{"depth != 2", eslintOnly, false},
+		{"depth < 2", eslintOnly, false},
+		{"depth <= 2", eslintOnly, true},
+		{"depth > 1", eslintOnly, true},
+		{"depth >= 3", eslintOnly, false},
+		{"depth == 0", root, true},
+
+		{"pathMatches('packages/*')", eslintOnly, true},
+		{`pathMatches("packages/*")`, eslintOnly, true},
+		{"pathMatches('packages')", eslintOnly, false},
+		{"pathMatches('**/web')", eslintOnly, true},
+		{"pathMatches('.')", root, true},
+		{"hasEslint&&depth<=2&&!pathMatches('tools')", eslintOnly, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := parseDirFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("parseDirFilter: %v", err)
+			}
+			if got := f(tt.attrs); got != tt.want {
+				t.Errorf("%s on %+v = %v, want %v", tt.expr, tt.attrs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDirFilterErrors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{"", "expected an attribute at end of expression"},
+		{"hasEslint &&", "at end of expression"},
+		{"hasEslint ||| hasPrettier", "position"},
+		{"(hasEslint", `expected ")"`},
+		{"hasEslint)", `unexpected ")" at position 10`},
+		{"hasEslint hasPrettier", `unexpected "hasPrettier"`},
+		{"hasEsLint", `unknown attribute "hasEsLint"`},
+		{"depth", "expected a comparison after depth"},
+		{"depth 2", "expected a comparison after depth"},
+		{"depth ! 2", "expected a comparison after depth"},
+		{"depth == x", "expected a number"},
+		{"pathMatches", `expected "("`},
+		{"pathMatches(x)", "expected a string"},
+		{"pathMatches('x'", `expected ")"`},
+		{"pathMatches('x)", "unterminated string"},
+		{"hasEslint & hasPrettier", "unexpected character '&'"},
+		{"2", "expected an attribute"},
+		{"!", "expected an attribute"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := parseDirFilter(tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseDirFilter(%q) error = %v, want one containing %q", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		glob, path string
+		want       bool
+	}{
+		{"*", "web", true},
+		{"*", "packages/web", false},
+		{"packages/*", "packages/web", true},
+		{"packages/*", "packages/web/src", false},
+		{"packages/?eb", "packages/web", true},
+		{"packages/?", "packages/ab", false},
+		{"?", "/", false},
+
+		{"**", "a/b/c", true},
+		{"**", ".", true},
+		{"**/web", "web", true},
+		{"**/web", "packages/web", true},
+		{"**/web", "a/b/web", true},
+		{"**/web", "a/bweb", false},
+		{"packages/**", "packages/a/b", true},
+		{"packages/**", "packages", false},
+		{"packages/**/src", "packages/src", true},
+		{"packages/**/src", "packages/a/b/src", true},
+		{"a**b", "a/x/b", true},
+
+		// Regexp metacharacters are literal.
+		{"a.b", "a.b", true},
+		{"a.b", "axb", false},
+		{"a+b", "a+b", true},
+		{"a+b", "aab", false},
+		{"(x)|y", "(x)|y", true},
+		{"(x)|y", "y", false},
+		{"[ab]", "[ab]", true},
+		{"[ab]", "a", false},
+		{"^$", "^$", true},
+		{"{a,b}", "{a,b}", true},
+		{`a\b`, `a\b`, true},
+
+		// Non-ASCII characters match literally, and ? matches one of them.
+		{"pakete/über", "pakete/über", true},
+		{"pakete/über", "pakete/uber", false},
+		{"pakete/?ber", "pakete/über", true},
+		{"pakete/??ber", "pakete/über", false},
+		{"*/日本*", "apps/日本語", true},
+		{"apps/?本語", "apps/日本語", true},
+		{"**/café", "a/b/café", true},
+
+		// Matches are anchored at both ends.
+		{"web", "packages/web", false},
+		{"packages", "packages/web", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.glob+" "+tt.path, func(t *testing.T) {
+			if got := globRegexp(tt.glob).MatchString(tt.path); got != tt.want {
+				t.Errorf("globRegexp(%q) matching %q = %v, want %v", tt.glob, tt.path, got, tt.want)
+			}
+		})
+	}
+}
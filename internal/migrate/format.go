@@ -0,0 +1,97 @@
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// ESLint config formats recorded in ConfigLocation.EslintFormats.
+const (
+	formatJSON        = "json"
+	formatYAML        = "yaml"
+	formatJS          = "js"
+	formatPackageJSON = "package.json"
+)
+
+// errUnsupportedFormat is recorded when biome migrate can't read a
+// location's ESLint config.
+var errUnsupportedFormat = errors.New("unsupported source format")
+
+// eslintFormat returns the format of the ESLint config file at path. A bare
+// .eslintrc may hold JSON or YAML, so its content decides.
+func eslintFormat(path string) string {
+	switch filepath.Ext(path) {
+	case ".js", ".cjs", ".mjs":
+		return formatJS
+	case ".yaml", ".yml":
+		return formatYAML
+	case ".json":
+		if filepath.Base(path) == "package.json" {
+			return formatPackageJSON
+		}
+		return formatJSON
+	}
+
+	data, err := os.ReadFile(path)
+	if err == nil && !json.Valid(stripJSONComments(data)) {
+		if _, err := parseYAML(data); err == nil {
+			return formatYAML
+		}
+	}
+	return formatJSON
+}
+
+// addFormat records format on loc once.
+func (loc *ConfigLocation) addFormat(format string) {
+	if !slices.Contains(loc.EslintFormats, format) {
+		loc.EslintFormats = append(loc.EslintFormats, format)
+	}
+}
+
+// onlyEslintFormat reports whether all of loc's ESLint configs are in
+// format.
+func (loc *ConfigLocation) onlyEslintFormat(format string) bool {
+	return len(loc.EslintFormats) > 0 && !slices.ContainsFunc(loc.EslintFormats, func(f string) bool { return f != format })
+}
+
+// eslintYAMLFile returns the first of loc's YAML ESLint configs.
+func (loc *ConfigLocation) eslintYAMLFile() string {
+	for _, path := range loc.Files {
+		if name := filepath.Base(path); slices.Contains(eslintConfigFiles, name) && eslintFormat(path) == formatYAML {
+			return path
+		}
+	}
+	return ""
+}
+
+// convertedEslintName is the temporary JSON config written next to a YAML
+// one for biome migrate to read.
+const convertedEslintName = ".eslintrc.json"
+
+// convertYAMLEslintConfig writes loc's YAML ESLint config as a JSON
+// .eslintrc.json in loc.Dir and returns its path. The caller removes it once
+// migrate has run.
+func convertYAMLEslintConfig(loc *ConfigLocation) (string, error) {
+	source := loc.eslintYAMLFile()
+	config, err := readEslintConfig(source)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", errUnsupportedFormat, err)
+	}
+	if config == nil {
+		config = map[string]any{}
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(loc.Dir, convertedEslintName)
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("%s already exists", path)
+	}
+	return path, os.WriteFile(path, data, 0o644)
+}
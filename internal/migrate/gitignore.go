@@ -0,0 +1,231 @@
+package migrate
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreRule is one .gitignore or -ignore pattern, matched against paths
+// relative to base.
+type ignoreRule struct {
+	base    string
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// parseIgnoreRule parses a pattern in .gitignore syntax. A pattern without a
+// slash matches a name at any depth below base; one with a slash is
+// anchored to base. A leading \# or \! stands for a literal # or !. It
+// returns false for blank lines and comments.
+func parseIgnoreRule(base, pattern string) (ignoreRule, bool) {
+	pattern = strings.TrimRight(pattern, " \t\r")
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	if pattern == "" {
+		return ignoreRule{}, false
+	}
+	if strings.Contains(pattern, "/") {
+		pattern = strings.TrimPrefix(pattern, "/")
+	} else {
+		pattern = "**/" + pattern
+	}
+	rule.re = ignoreRegexp(pattern)
+	return rule, true
+}
+
+// ignoreRegexp compiles a slash-separated .gitignore glob into an anchored
+// regexp. On top of the globRegexp wildcards it supports bracket classes
+// such as [cod], [a-z] or [!0-9], and a backslash makes the next character
+// literal. A [ without its closing ] is literal too.
+func ignoreRegexp(pattern string) *regexp.Regexp {
+	runes := []rune(pattern)
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+			}
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				i++
+				if i+1 < len(runes) && runes[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			if class, n := bracketClass(runes[i:]); n > 0 {
+				b.WriteString(class)
+				i += n - 1
+			} else {
+				b.WriteString(`\[`)
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// bracketClass translates the bracket expression at the start of runes
+// into a regexp character class and returns it with the number of runes it
+// took, or 0 when the expression isn't closed. A leading ! or ^ negates
+// the class, a ] right after the opening bracket is literal, and the class
+// never matches a slash.
+func bracketClass(runes []rune) (string, int) {
+	i := 1
+	negate := i < len(runes) && (runes[i] == '!' || runes[i] == '^')
+	if negate {
+		i++
+	}
+	var class strings.Builder
+	for start := i; i < len(runes); i++ {
+		c := runes[i]
+		if c == ']' && i > start {
+			break
+		}
+		if c == '\\' && i+1 < len(runes) {
+			i++
+			c = runes[i]
+		}
+		if i+2 < len(runes) && runes[i+1] == '-' && runes[i+2] != ']' {
+			hi := runes[i+2]
+			i += 2
+			if hi == '\\' && i+1 < len(runes) {
+				i++
+				hi = runes[i]
+			}
+			classRange(&class, c, hi)
+			continue
+		}
+		classRange(&class, c, c)
+	}
+	if i >= len(runes) {
+		return "", 0
+	}
+
+	switch {
+	case negate:
+		return "[^/" + class.String() + "]", i + 1
+	case class.Len() == 0:
+		// Nothing but slashes or empty ranges: the class matches nothing.
+		return `[^\x00-\x{10ffff}]`, i + 1
+	default:
+		return "[" + class.String() + "]", i + 1
+	}
+}
+
+// classRange adds the runes from lo to hi, except the slash, to class. A
+// range with lo above hi adds nothing, as in git.
+func classRange(class *strings.Builder, lo, hi rune) {
+	if lo <= '/' && '/' <= hi {
+		classRange(class, lo, '/'-1)
+		classRange(class, '/'+1, hi)
+		return
+	}
+	if lo <= hi {
+		fmt.Fprintf(class, `\x{%x}-\x{%x}`, lo, hi)
+	}
+}
+
+// readGitignore reads the rules of the .gitignore in dir. A missing file
+// yields no rules.
+func readGitignore(dir string) ([]ignoreRule, error) {
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(dir, scanner.Text()); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, scanner.Err()
+}
+
+// ancestorGitignores returns the rules of the .gitignore files above root,
+// up to the top of its git work tree. Outside a work tree there are none.
+func ancestorGitignores(root string) ([]ignoreRule, error) {
+	if isGitTop(root) {
+		return nil, nil
+	}
+	var dirs []string
+	for dir := filepath.Dir(root); ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+		if isGitTop(dir) {
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			return nil, nil
+		}
+	}
+
+	var rules []ignoreRule
+	for i := len(dirs) - 1; i >= 0; i-- {
+		dirRules, err := readGitignore(dirs[i])
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, dirRules...)
+	}
+	return rules, nil
+}
+
+// isGitTop reports whether dir is the top of a git work tree.
+func isGitTop(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// ignored reports whether path is excluded by rules. As in git, the last
+// matching rule wins, so a later negated pattern re-includes a path.
+func ignored(rules []ignoreRule, path string, isDir bool) bool {
+	excluded := false
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		rel, err := filepath.Rel(rule.base, path)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if rule.re.MatchString(filepath.ToSlash(rel)) {
+			excluded = !rule.negate
+		}
+	}
+	return excluded
+}
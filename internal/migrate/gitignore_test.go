@@ -0,0 +1,121 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnored(t *testing.T) {
+	base := filepath.FromSlash("/repo")
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		isDir    bool
+		want     bool
+	}{
+		{"bare name at any depth", []string{"build"}, "a/b/build", true, true},
+		{"slash anchors to base", []string{"/build"}, "a/build", true, false},
+		{"anchored match", []string{"packages/*/out"}, "packages/web/out", true, true},
+		{"comment", []string{"# build"}, "# build", false, false},
+		{"blank line", []string{"", "  "}, "build", true, false},
+
+		{"dir-only matches dir", []string{"out/"}, "web/out", true, true},
+		{"dir-only skips file", []string{"out/"}, "web/out", false, false},
+		{"negation re-includes", []string{"*.log", "!keep.log"}, "keep.log", false, false},
+		{"negation only for its match", []string{"*.log", "!keep.log"}, "drop.log", false, true},
+		{"last rule wins", []string{"!keep.log", "*.log"}, "keep.log", false, true},
+
+		{"bracket class", []string{"*.py[cod]"}, "lib/x.pyc", false, true},
+		{"bracket class miss", []string{"*.py[cod]"}, "lib/x.py", false, false},
+		{"bracket range", []string{"v[0-9]"}, "v7", true, true},
+		{"bracket range miss", []string{"v[0-9]"}, "vx", true, false},
+		{"negated class with !", []string{"v[!0-9]"}, "vx", true, true},
+		{"negated class with ^", []string{"v[^0-9]"}, "v7", true, false},
+		{"literal ] first", []string{"a[]]"}, "a]", false, true},
+		{"escaped ] in class", []string{`a[\]x]`}, "ax", false, true},
+		{"class never matches slash", []string{"a[/]b"}, "a/b", false, false},
+		{"range never matches slash", []string{"a[+-0]b"}, "a/b", false, false},
+		{"unclosed bracket is literal", []string{"a[b"}, "a[b", false, true},
+
+		{"escaped hash", []string{`\#notes`}, "#notes", false, true},
+		{"escaped bang", []string{`\!important`}, "!important", false, true},
+		{"escaped bang is no negation", []string{"*", `\!keep`}, "!keep", false, true},
+		{"escaped star", []string{`a\*`}, "ab", false, false},
+
+		{"non-ASCII name", []string{"über"}, "pakete/über", true, true},
+		{"non-ASCII ? matches one rune", []string{"?ber"}, "über", true, true},
+		{"non-ASCII class", []string{"[äöü]ber"}, "über", true, true},
+		{"non-ASCII range", []string{"[あ-ん]"}, "か", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules []ignoreRule
+			for _, pattern := range tt.patterns {
+				if rule, ok := parseIgnoreRule(base, pattern); ok {
+					rules = append(rules, rule)
+				}
+			}
+			path := filepath.Join(base, filepath.FromSlash(tt.path))
+			if got := ignored(rules, path, tt.isDir); got != tt.want {
+				t.Errorf("ignored(%q, %q) = %v, want %v", tt.patterns, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// Nested .gitignore files apply relative to their own directory, on top of
+// the ones above them.
+func TestFindConfigsGitignore(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, root, map[string]string{
+		".gitignore":                      "gen/\n.prettierrc\n",
+		"gen/.eslintrc.json":              `{}`,
+		"legacy/.eslintrc.json":           `{}`,
+		"apps/web/.gitignore":             "legacy\n",
+		"apps/web/.eslintrc.json":         `{}`,
+		"apps/web/.prettierrc":            `{}`,
+		"apps/web/legacy/.eslintrc.json":  `{}`,
+		"apps/api/.gitignore":             "!.prettierrc\n",
+		"apps/api/.prettierrc":            `{}`,
+		"apps/lib/.gitignore":             ".eslintrc.json/\n",
+		"apps/lib/.eslintrc.json":         `{}`,
+		"apps/lib/gen/.eslintrc.json":     `{}`,
+		"apps/lib/gen.d/.eslintrc.json":   `{}`,
+		"apps/api/scripts/.eslintrc.json": `{}`,
+	})
+
+	locations, err := findConfigs(root, scanOptions{gitignore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		dir              string
+		found            bool
+		eslint, prettier bool
+	}{
+		{dir: "gen"},
+		{dir: "legacy", found: true, eslint: true},
+		{dir: "apps/web", found: true, eslint: true},
+		{dir: "apps/web/legacy"},
+		{dir: "apps/api", found: true, prettier: true},
+		{dir: "apps/api/scripts", found: true, eslint: true},
+		{dir: "apps/lib", found: true, eslint: true},
+		{dir: "apps/lib/gen"},
+		{dir: "apps/lib/gen.d", found: true, eslint: true},
+	}
+	for _, tt := range tests {
+		loc := locations[filepath.Join(root, filepath.FromSlash(tt.dir))]
+		if (loc != nil) != tt.found {
+			t.Errorf("found %s = %v, want %v", tt.dir, loc != nil, tt.found)
+			continue
+		}
+		if loc != nil && (loc.HasEslint != tt.eslint || loc.HasPrettier != tt.prettier) {
+			t.Errorf("%s: HasEslint, HasPrettier = %v, %v, want %v, %v", tt.dir, loc.HasEslint, loc.HasPrettier, tt.eslint, tt.prettier)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package migrate
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// errGitNotFound is returned by addToGlobalGitignore when git isn't on PATH.
+var errGitNotFound = errors.New("git is not installed")
+
+// defaultGlobalGitignore is the excludes file set up when git has none
+// configured.
+const defaultGlobalGitignore = "~/.gitignore_global"
+
+// globalGitignorePath returns the file named by git's global
+// core.excludesfile, with ~ expanded, or "" when it isn't set.
+func globalGitignorePath() (string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", errGitNotFound
+	}
+	output, err := exec.Command("git", "config", "--global", "--path", "core.excludesfile").Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		// Exit status 1 means the key is unset.
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// addToGlobalGitignore appends entry to the global gitignore for
+// -update-gitignore, first creating ~/.gitignore_global and pointing
+// core.excludesfile at it when none is configured. It returns the file and
+// whether entry was added; an entry already listed is left alone.
+func addToGlobalGitignore(entry string) (path string, added bool, err error) {
+	path, err = globalGitignorePath()
+	if err != nil {
+		return "", false, err
+	}
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false, err
+		}
+		if err := exec.Command("git", "config", "--global", "core.excludesfile", defaultGlobalGitignore).Run(); err != nil {
+			return "", false, err
+		}
+		path = filepath.Join(home, strings.TrimPrefix(defaultGlobalGitignore, "~/"))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return path, false, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line == entry || line == "/"+entry {
+			return path, false, nil
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return path, false, err
+	}
+	line := entry + "\n"
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		line = "\n" + line
+	}
+	if _, err := f.WriteString(line); err != nil {
+		f.Close()
+		return path, false, err
+	}
+	return path, true, f.Close()
+}
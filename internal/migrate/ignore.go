@@ -0,0 +1,113 @@
+package migrate
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// legacyIgnoreFiles are the ignore files whose patterns are carried over
+// into files.ignore and a generated .biomeignore.
+var legacyIgnoreFiles = []string{".eslintignore", ".prettierignore"}
+
+// readIgnorePatterns returns the patterns in an ignore file, skipping blank
+// lines and # comments. A missing file yields no patterns.
+func readIgnorePatterns(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, scanner.Err()
+}
+
+// biomeignorePatterns merges the patterns for dir's .biomeignore: the
+// template first, then any existing .biomeignore, then the legacy ignore
+// files. Duplicates keep their first position.
+func biomeignorePatterns(dir string, template []string) ([]string, error) {
+	sources := []string{filepath.Join(dir, ".biomeignore")}
+	for _, name := range legacyIgnoreFiles {
+		sources = append(sources, filepath.Join(dir, name))
+	}
+
+	seen := make(map[string]bool)
+	var merged []string
+	add := func(patterns []string) {
+		for _, p := range patterns {
+			if !seen[p] {
+				seen[p] = true
+				merged = append(merged, p)
+			}
+		}
+	}
+
+	add(template)
+	for _, path := range sources {
+		patterns, err := readIgnorePatterns(path)
+		if err != nil {
+			return nil, err
+		}
+		add(patterns)
+	}
+	return merged, nil
+}
+
+// legacyIgnorePatterns returns the union of the patterns in loc's
+// .eslintignore and .prettierignore, in file order without duplicates.
+// Unreadable files are skipped.
+func legacyIgnorePatterns(loc *ConfigLocation) []string {
+	seen := make(map[string]bool)
+	var merged []string
+	for _, path := range loc.IgnoreFiles {
+		patterns, err := readIgnorePatterns(path)
+		if err != nil {
+			continue
+		}
+		for _, p := range patterns {
+			if !seen[p] {
+				seen[p] = true
+				merged = append(merged, p)
+			}
+		}
+	}
+	return merged
+}
+
+// addFilesIgnore appends the patterns missing from config's files.ignore,
+// creating it if needed.
+func addFilesIgnore(config map[string]any, patterns []string) {
+	files, _ := config["files"].(map[string]any)
+	if files == nil {
+		files = map[string]any{}
+		config["files"] = files
+	}
+	existing, _ := files["ignore"].([]any)
+	for _, p := range patterns {
+		if !slices.Contains(existing, any(p)) {
+			existing = append(existing, p)
+		}
+	}
+	files["ignore"] = existing
+}
+
+// writeBiomeignore writes patterns to dir's .biomeignore.
+func writeBiomeignore(dir string, patterns []string) error {
+	content := "# Generated by biome_configurator\n" + strings.Join(patterns, "\n") + "\n"
+	return os.WriteFile(filepath.Join(dir, ".biomeignore"), []byte(content), 0o644)
+}
@@ -0,0 +1,53 @@
+package migrate
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// confirmer asks before each location is migrated, for -interactive. A nil
+// *confirmer approves everything.
+type confirmer struct {
+	in *bufio.Reader
+	// all is set once the user answered "a"; quit once they answered "q".
+	all  bool
+	quit bool
+}
+
+// newConfirmer returns a confirmer reading answers from stdin, or nil when
+// stdin isn't a terminal, so unattended runs never wait for input.
+func newConfirmer() *confirmer {
+	if !isTerminal(os.Stdin) {
+		return nil
+	}
+	return &confirmer{in: bufio.NewReader(os.Stdin)}
+}
+
+// confirm prints loc's tools and asks whether to migrate it. Anything but
+// y, a or q declines; end of input counts as q.
+func (c *confirmer) confirm(out *printer, loc *ConfigLocation) bool {
+	if c == nil || c.all {
+		return true
+	}
+	if c.quit {
+		return false
+	}
+
+	out.printf("\n%s [%s]\nMigrate this directory? [y/N/a/q] ", loc.displayDir(), strings.Join(loc.tools(), ", "))
+	answer, err := c.in.ReadString('\n')
+	if err == io.EOF && answer == "" {
+		answer = "q"
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	case "a", "all":
+		c.all = true
+		return true
+	case "q", "quit":
+		c.quit = true
+	}
+	return false
+}
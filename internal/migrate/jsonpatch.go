@@ -0,0 +1,277 @@
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// jsonPatchOp is one operation of an RFC 6902 JSON Patch document.
+type jsonPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+
+	value any
+}
+
+// loadJSONPatch reads and validates an RFC 6902 patch document.
+func loadJSONPatch(path string) ([]jsonPatchOp, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []jsonPatchOp
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for i := range ops {
+		op := &ops[i]
+		if err := op.validate(); err != nil {
+			return nil, fmt.Errorf("%s: operation %d (%s %s): %w", path, i, op.Op, op.Path, err)
+		}
+	}
+	return ops, nil
+}
+
+func (op *jsonPatchOp) validate() error {
+	if _, err := parsePointer(op.Path); err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return errors.New(`missing "value"`)
+		}
+		return json.Unmarshal(op.Value, &op.value)
+	case "move", "copy":
+		if _, err := parsePointer(op.From); err != nil {
+			return fmt.Errorf(`"from": %w`, err)
+		}
+		if op.Op == "move" && strings.HasPrefix(op.Path, op.From+"/") {
+			return errors.New("cannot move a value into one of its children")
+		}
+		return nil
+	case "remove":
+		return nil
+	default:
+		return fmt.Errorf("unknown op %q", op.Op)
+	}
+}
+
+// applyJSONPatch applies ops to config in order. The error names the
+// operation that failed.
+func applyJSONPatch(config map[string]any, ops []jsonPatchOp) (map[string]any, error) {
+	var doc any = config
+	for i, op := range ops {
+		var err error
+		doc, err = op.apply(doc)
+		if err != nil {
+			return nil, fmt.Errorf("json patch operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	result, ok := doc.(map[string]any)
+	if !ok {
+		return nil, errors.New("json patch must leave the config a JSON object")
+	}
+	return result, nil
+}
+
+func (op jsonPatchOp) apply(doc any) (any, error) {
+	path, _ := parsePointer(op.Path)
+
+	switch op.Op {
+	case "add":
+		return pointerAdd(doc, path, cloneValue(op.value))
+	case "remove":
+		return pointerRemove(doc, path)
+	case "replace":
+		if _, err := pointerGet(doc, path); err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			return cloneValue(op.value), nil
+		}
+		doc, err := pointerRemove(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, path, cloneValue(op.value))
+	case "move":
+		from, _ := parsePointer(op.From)
+		value, err := pointerGet(doc, from)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		if doc, err = pointerRemove(doc, from); err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		return pointerAdd(doc, path, value)
+	case "copy":
+		from, _ := parsePointer(op.From)
+		value, err := pointerGet(doc, from)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		return pointerAdd(doc, path, cloneValue(value))
+	case "test":
+		value, err := pointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(value, op.value) {
+			return nil, errors.New("test failed: value differs")
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		if strings.Count(token, "~") != strings.Count(token, "~0")+strings.Count(token, "~1") {
+			return nil, fmt.Errorf("invalid escape in JSON pointer %q", pointer)
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func pointerGet(doc any, path []string) (any, error) {
+	for i, token := range path {
+		switch node := doc.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, missingPath(path[:i+1])
+			}
+			doc = value
+		case []any:
+			index, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", formatPointer(path[:i+1]), err)
+			}
+			doc = node[index]
+		default:
+			return nil, missingPath(path[:i+1])
+		}
+	}
+	return doc, nil
+}
+
+func pointerAdd(doc any, path []string, value any) (any, error) {
+	return pointerUpdate(doc, path, func(parent any, key string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			node[key] = value
+			return node, nil
+		case []any:
+			if key == "-" {
+				return append(node, value), nil
+			}
+			index, err := arrayIndex(key, len(node))
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		}
+		return nil, missingPath(path)
+	}, value)
+}
+
+func pointerRemove(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, errors.New("cannot remove the whole document")
+	}
+	return pointerUpdate(doc, path, func(parent any, key string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			if _, ok := node[key]; !ok {
+				return nil, missingPath(path)
+			}
+			delete(node, key)
+			return node, nil
+		case []any:
+			index, err := arrayIndex(key, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:index], node[index+1:]...), nil
+		}
+		return nil, missingPath(path)
+	}, nil)
+}
+
+// pointerUpdate walks to the parent of path, lets update modify it and
+// stores the returned container back into its own parent, since updating an
+// array may reallocate it. An empty path replaces the document with root.
+func pointerUpdate(doc any, path []string, update func(parent any, key string) (any, error), root any) (any, error) {
+	if len(path) == 0 {
+		return root, nil
+	}
+	parent, err := pointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	updated, err := update(parent, path[len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	if len(path) == 1 {
+		return updated, nil
+	}
+	return pointerUpdate(doc, path[:len(path)-1], func(grandparent any, key string) (any, error) {
+		switch node := grandparent.(type) {
+		case map[string]any:
+			node[key] = updated
+			return node, nil
+		case []any:
+			index, _ := arrayIndex(key, len(node)-1)
+			node[index] = updated
+			return node, nil
+		}
+		return nil, missingPath(path)
+	}, updated)
+}
+
+// arrayIndex parses an array index token that must not exceed max.
+func arrayIndex(token string, max int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || strings.TrimLeft(token, "0123456789") != "" || index < 0 || index > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	return index, nil
+}
+
+func missingPath(path []string) error {
+	return fmt.Errorf("path %s does not exist", formatPointer(path))
+}
+
+func formatPointer(path []string) string {
+	var b strings.Builder
+	for _, token := range path {
+		b.WriteString("/")
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
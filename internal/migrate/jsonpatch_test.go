@@ -0,0 +1,139 @@
+package migrate
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// decodePatch parses and validates a patch document as loadJSONPatch does.
+func decodePatch(t *testing.T, src string) []jsonPatchOp {
+	t.Helper()
+	var ops []jsonPatchOp
+	if err := json.Unmarshal([]byte(src), &ops); err != nil {
+		t.Fatal(err)
+	}
+	for i := range ops {
+		if err := ops[i].validate(); err != nil {
+			t.Fatalf("operation %d: %v", i, err)
+		}
+	}
+	return ops
+}
+
+func decodeJSONObject(t *testing.T, src string) map[string]any {
+	t.Helper()
+	var m map[string]any
+	if err := json.Unmarshal([]byte(src), &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	const doc = `{"a": {"b": 1, "c": [1, 2, 3]}, "x~y": 1, "p/q": 2}`
+
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{"add member", `[{"op": "add", "path": "/vcs", "value": {"enabled": true}}]`,
+			`{"a": {"b": 1, "c": [1, 2, 3]}, "x~y": 1, "p/q": 2, "vcs": {"enabled": true}}`},
+		{"add replaces member", `[{"op": "add", "path": "/a/b", "value": 5}]`,
+			`{"a": {"b": 5, "c": [1, 2, 3]}, "x~y": 1, "p/q": 2}`},
+		{"add inserts into array", `[{"op": "add", "path": "/a/c/1", "value": 9}]`,
+			`{"a": {"b": 1, "c": [1, 9, 2, 3]}, "x~y": 1, "p/q": 2}`},
+		{"add appends with -", `[{"op": "add", "path": "/a/c/-", "value": 4}]`,
+			`{"a": {"b": 1, "c": [1, 2, 3, 4]}, "x~y": 1, "p/q": 2}`},
+		{"add at array end index", `[{"op": "add", "path": "/a/c/3", "value": 4}]`,
+			`{"a": {"b": 1, "c": [1, 2, 3, 4]}, "x~y": 1, "p/q": 2}`},
+		{"remove member", `[{"op": "remove", "path": "/a/b"}]`,
+			`{"a": {"c": [1, 2, 3]}, "x~y": 1, "p/q": 2}`},
+		{"remove array element", `[{"op": "remove", "path": "/a/c/0"}]`,
+			`{"a": {"b": 1, "c": [2, 3]}, "x~y": 1, "p/q": 2}`},
+		{"replace", `[{"op": "replace", "path": "/a/c", "value": "none"}]`,
+			`{"a": {"b": 1, "c": "none"}, "x~y": 1, "p/q": 2}`},
+		{"replace root", `[{"op": "replace", "path": "", "value": {"z": 1}}]`,
+			`{"z": 1}`},
+		{"move", `[{"op": "move", "from": "/a/b", "path": "/b"}]`,
+			`{"a": {"c": [1, 2, 3]}, "b": 1, "x~y": 1, "p/q": 2}`},
+		{"move array element", `[{"op": "move", "from": "/a/c/0", "path": "/a/c/-"}]`,
+			`{"a": {"b": 1, "c": [2, 3, 1]}, "x~y": 1, "p/q": 2}`},
+		{"copy", `[{"op": "copy", "from": "/a/c", "path": "/d"}]`,
+			`{"a": {"b": 1, "c": [1, 2, 3]}, "d": [1, 2, 3], "x~y": 1, "p/q": 2}`},
+		{"test passes", `[{"op": "test", "path": "/a/c", "value": [1, 2, 3]}, {"op": "remove", "path": "/a"}]`,
+			`{"x~y": 1, "p/q": 2}`},
+		{"~0 unescapes to ~", `[{"op": "replace", "path": "/x~0y", "value": 3}]`,
+			`{"a": {"b": 1, "c": [1, 2, 3]}, "x~y": 3, "p/q": 2}`},
+		{"~1 unescapes to /", `[{"op": "remove", "path": "/p~1q"}]`,
+			`{"a": {"b": 1, "c": [1, 2, 3]}, "x~y": 1}`},
+		{"operations apply in order", `[{"op": "add", "path": "/n", "value": {}}, {"op": "add", "path": "/n/m", "value": 1}]`,
+			`{"a": {"b": 1, "c": [1, 2, 3]}, "x~y": 1, "p/q": 2, "n": {"m": 1}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyJSONPatch(decodeJSONObject(t, doc), decodePatch(t, tt.patch))
+			if err != nil {
+				t.Fatalf("applyJSONPatch: %v", err)
+			}
+			if want := decodeJSONObject(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("applyJSONPatch = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestApplyJSONPatchErrors(t *testing.T) {
+	const doc = `{"a": {"b": 1, "c": [1, 2, 3]}}`
+
+	tests := []struct {
+		name    string
+		patch   string
+		wantErr string
+	}{
+		{"remove missing path", `[{"op": "remove", "path": "/a/nope"}]`, "operation 0 (remove /a/nope)"},
+		{"replace missing path", `[{"op": "replace", "path": "/z", "value": 1}]`, "operation 0 (replace /z)"},
+		{"add under missing parent", `[{"op": "add", "path": "/z/y", "value": 1}]`, "operation 0 (add /z/y)"},
+		{"move from missing path", `[{"op": "move", "from": "/z", "path": "/y"}]`, "from"},
+		{"failing test op", `[{"op": "add", "path": "/d", "value": 1}, {"op": "test", "path": "/a/b", "value": 2}]`, "operation 1 (test /a/b)"},
+		{"index out of range", `[{"op": "add", "path": "/a/c/4", "value": 1}]`, "operation 0"},
+		{"remove index out of range", `[{"op": "remove", "path": "/a/c/3"}]`, "operation 0"},
+		{"remove with -", `[{"op": "remove", "path": "/a/c/-"}]`, "operation 0"},
+		{"non-numeric index", `[{"op": "replace", "path": "/a/c/one", "value": 1}]`, "operation 0"},
+		{"signed index", `[{"op": "remove", "path": "/a/c/+1"}]`, "operation 0"},
+		{"leading zero index", `[{"op": "remove", "path": "/a/c/01"}]`, "operation 0"},
+		{"root replaced by non-object", `[{"op": "replace", "path": "", "value": [1]}]`, "JSON object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := applyJSONPatch(decodeJSONObject(t, doc), decodePatch(t, tt.patch))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("applyJSONPatch error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJSONPatchValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		op   jsonPatchOp
+	}{
+		{"unknown op", jsonPatchOp{Op: "merge", Path: "/a"}},
+		{"pointer without leading slash", jsonPatchOp{Op: "remove", Path: "a"}},
+		{"bad escape", jsonPatchOp{Op: "remove", Path: "/a~2"}},
+		{"trailing ~", jsonPatchOp{Op: "remove", Path: "/a~"}},
+		{"add without value", jsonPatchOp{Op: "add", Path: "/a"}},
+		{"move into own child", jsonPatchOp{Op: "move", From: "/a", Path: "/a/b"}},
+		{"copy with bad from", jsonPatchOp{Op: "copy", From: "a", Path: "/b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.op.validate(); err == nil {
+				t.Errorf("validate(%+v) = nil, want an error", tt.op)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Values of -log-format. The default, plain, is the tool's usual
+// human-oriented output; text and json emit log/slog records instead.
+const (
+	logFormatPlain = "plain"
+	logFormatText  = "text"
+	logFormatJSON  = "json"
+)
+
+var logFormats = []string{logFormatPlain, logFormatText, logFormatJSON}
+
+// logLevels are the accepted -log-level values.
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+// newLogger returns a logger writing records at level and above to w in
+// format, each with a run_id attribute when runID is set, or nil for the
+// plain format.
+func newLogger(format string, level slog.Level, w io.Writer, runID string) *slog.Logger {
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	var logger *slog.Logger
+	switch format {
+	case logFormatText:
+		logger = slog.New(slog.NewTextHandler(w, handlerOpts))
+	case logFormatJSON:
+		logger = slog.New(slog.NewJSONHandler(w, handlerOpts))
+	default:
+		return nil
+	}
+	if runID != "" {
+		logger = logger.With("run_id", runID)
+	}
+	return logger
+}
+
+// prefixLines puts prefix in front of every non-empty line of msg.
+func prefixLines(prefix, msg string) string {
+	lines := strings.SplitAfter(msg, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// emit writes a message at level: as a record when structured logging is
+// on, otherwise as is, but for debug messages, the verbose output, whose
+// lines name the run. A record's message is trimmed of the blank lines and
+// indentation that lay out the plain output, and of a "Warning: " prefix
+// its level already conveys.
+func (p *printer) emit(level slog.Level, format string, args ...any) {
+	if p.log == nil && level == slog.LevelDebug && p.runID != "" {
+		p.printf("%s", prefixLines("["+p.runID+"] ", fmt.Sprintf(format, args...)))
+		return
+	}
+	if p.log == nil {
+		p.printf(format, args...)
+		return
+	}
+	msg := strings.TrimSpace(fmt.Sprintf(format, args...))
+	msg = strings.TrimPrefix(msg, "Warning: ")
+	if msg == "" {
+		return
+	}
+	p.progress.around(func() []byte {
+		p.log.Log(context.Background(), level, msg)
+		return nil
+	})
+}
+
+// logAttrs writes a record with attributes when structured logging is on
+// and reports whether it did, so callers can fall back to plain output.
+func (p *printer) logAttrs(level slog.Level, msg string, attrs ...any) bool {
+	if p.log == nil {
+		return false
+	}
+	p.progress.around(func() []byte {
+		p.log.Log(context.Background(), level, msg, attrs...)
+		return nil
+	})
+	return true
+}
+
+// childOutput passes on the output of a biome command. With structured
+// logging it goes to stderr untouched instead of into a record, so Biome's
+// own formatting survives.
+func (p *printer) childOutput(output []byte) {
+	if p.log == nil {
+		p.printf("%s", output)
+		return
+	}
+	p.progress.around(func() []byte {
+		os.Stderr.Write(output)
+		return output
+	})
+}
+
+// childWriter returns where a biome command's output streams with -v.
+func (p *printer) childWriter() io.Writer {
+	if p.log != nil {
+		return os.Stderr
+	}
+	return p.w
+}
@@ -0,0 +1,43 @@
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLoggerAddsRunID(t *testing.T) {
+	var buf bytes.Buffer
+	out := &printer{w: &buf, level: slog.LevelInfo, logFormat: logFormatJSON, runID: "R1"}
+	out.log = newLogger(out.logFormat, out.level, &buf, out.runID)
+	out.infof("Found configs\n")
+	out.bufferedTo(&buf).warnf("Warning: from a worker\n")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d records, want 2:\n%s", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatal(err)
+		}
+		if record["run_id"] != "R1" {
+			t.Errorf("record %s has no run_id R1", line)
+		}
+	}
+}
+
+func TestVerboseLinesNameRun(t *testing.T) {
+	var buf bytes.Buffer
+	out := &printer{w: &buf, verbose: true, runID: "R1"}
+	out.verbosef("\n  $ biome migrate\n  done\n")
+	out.infof("Created: biome.json\n")
+
+	want := "\n[R1]   $ biome migrate\n[R1]   done\nCreated: biome.json\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
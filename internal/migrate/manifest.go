@@ -0,0 +1,137 @@
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// readPackageJSON reads the package.json in dir, keeping each top-level
+// value undecoded. A missing file yields a nil map.
+func readPackageJSON(dir string) (map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest map[string]json.RawMessage
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Join(dir, "package.json"), err)
+	}
+	return manifest, nil
+}
+
+// embeddedConfigs reports whether the package.json at path carries an
+// "eslintConfig" or "prettier" key. An empty object still counts; a null
+// value or an unreadable manifest doesn't.
+func embeddedConfigs(path string) (hasEslint, hasPrettier bool) {
+	manifest, err := readPackageJSON(filepath.Dir(path))
+	if err != nil {
+		return false, false
+	}
+	present := func(key string) bool {
+		value, ok := manifest[key]
+		return ok && string(value) != "null"
+	}
+	return present("eslintConfig"), present("prettier")
+}
+
+// sharedPrettierConfig returns the package named by a string "prettier"
+// key, e.g. "@org/prettier-config", which points at a shareable config
+// instead of holding the settings inline.
+func sharedPrettierConfig(manifest map[string]json.RawMessage) (string, bool) {
+	var pkg string
+	if err := json.Unmarshal(manifest["prettier"], &pkg); err != nil || pkg == "" {
+		return "", false
+	}
+	return pkg, true
+}
+
+// resolveSharedConfig finds the shareable config spec under the nearest
+// node_modules at or above dir and returns the file it resolves to along
+// with its settings when the file is JSON. Settings defined in JavaScript
+// can't be read and come back nil.
+func resolveSharedConfig(dir, spec string) (string, map[string]any, error) {
+	for d := dir; ; d = filepath.Dir(d) {
+		base := filepath.Join(d, "node_modules", filepath.FromSlash(spec))
+		if path, ok := resolvePackageFile(base); ok {
+			if !strings.HasSuffix(path, ".json") {
+				return path, nil, nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return path, nil, err
+			}
+			var settings map[string]any
+			if err := json.Unmarshal(data, &settings); err != nil {
+				return path, nil, fmt.Errorf("%s: %w", path, err)
+			}
+			return path, settings, nil
+		}
+		if filepath.Dir(d) == d {
+			return "", nil, fmt.Errorf("%s is not installed under any node_modules above %s", spec, dir)
+		}
+	}
+}
+
+// resolvePackageFile mimics Node's resolution of a require path: the path
+// itself, with a .json or .js extension, or a directory's package.json
+// "main" or index file.
+func resolvePackageFile(base string) (string, bool) {
+	for _, candidate := range []string{base, base + ".json", base + ".js"} {
+		if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() {
+			return candidate, true
+		}
+	}
+
+	if manifest, err := readPackageJSON(base); err == nil && manifest != nil {
+		var main string
+		if json.Unmarshal(manifest["main"], &main) == nil && main != "" {
+			if path, ok := resolvePackageFile(filepath.Join(base, main)); ok {
+				return path, true
+			}
+		}
+	}
+
+	for _, index := range []string{"index.json", "index.js", "index.cjs"} {
+		candidate := filepath.Join(base, index)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// reportSharedConfigs warns when loc's package.json delegates its Prettier
+// config to a shareable config package, which biome migrate may not follow,
+// and reports what the package resolves to.
+func reportSharedConfigs(loc *ConfigLocation, out *printer) {
+	manifest, err := readPackageJSON(loc.Dir)
+	if err != nil {
+		out.warnf("Warning: could not read package.json in %s: %v\n", loc.Dir, err)
+		return
+	}
+
+	spec, ok := sharedPrettierConfig(manifest)
+	if !ok {
+		return
+	}
+
+	out.warnf("Warning: %s uses the shared Prettier config %q from a dependency; biome migrate may not resolve it\n", loc.Dir, spec)
+	path, settings, err := resolveSharedConfig(loc.Dir, spec)
+	switch {
+	case err != nil:
+		out.errorf("  could not resolve %s: %v\n", spec, err)
+	case settings == nil:
+		out.infof("  %s resolves to %s, which is JavaScript and can't be read\n", spec, path)
+	default:
+		encoded, _ := json.Marshal(settings)
+		out.infof("  %s resolves to %s with settings %s\n", spec, path, encoded)
+	}
+}
@@ -0,0 +1,49 @@
+package migrate
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFindConfigsPackageJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		manifest     string
+		found        bool
+		wantEslint   bool
+		wantPrettier bool
+	}{
+		{name: "no config keys", manifest: `{"name": "app"}`},
+		{name: "eslintConfig", manifest: `{"eslintConfig": {"extends": "eslint:recommended"}}`, found: true, wantEslint: true},
+		{name: "prettier", manifest: `{"prettier": {"semi": false}}`, found: true, wantPrettier: true},
+		{name: "empty prettier object", manifest: `{"prettier": {}}`, found: true, wantPrettier: true},
+		{name: "shared prettier config", manifest: `{"prettier": "@org/prettier-config"}`, found: true, wantPrettier: true},
+		{name: "both", manifest: `{"eslintConfig": {}, "prettier": {}}`, found: true, wantEslint: true, wantPrettier: true},
+		{name: "null keys", manifest: `{"eslintConfig": null, "prettier": null}`},
+		{name: "invalid JSON", manifest: `{"prettier": {`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, map[string]string{"package.json": tt.manifest})
+			locations, err := findConfigs(root, scanOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			loc := locations[root]
+			if (loc != nil) != tt.found {
+				t.Fatalf("location found = %v, want %v", loc != nil, tt.found)
+			}
+			if loc == nil {
+				return
+			}
+			if loc.HasEslint != tt.wantEslint || loc.HasPrettier != tt.wantPrettier {
+				t.Errorf("HasEslint, HasPrettier = %v, %v, want %v, %v", loc.HasEslint, loc.HasPrettier, tt.wantEslint, tt.wantPrettier)
+			}
+			if got := slices.Contains(loc.EslintFormats, formatPackageJSON); got != tt.wantEslint {
+				t.Errorf("EslintFormats = %v, want package.json listed: %v", loc.EslintFormats, tt.wantEslint)
+			}
+		})
+	}
+}
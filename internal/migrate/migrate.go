@@ -0,0 +1,1515 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+var eslintConfigFiles = []string{
+	".eslintrc.json",
+	".eslintrc.js",
+	".eslintrc.cjs",
+	".eslintrc.yaml",
+	".eslintrc.yml",
+	".eslintrc",
+	"eslint.config.js",
+	"eslint.config.mjs",
+	"eslint.config.cjs",
+}
+
+var prettierConfigFiles = []string{
+	".prettierrc",
+	".prettierrc.json",
+	".prettierrc.yml",
+	".prettierrc.yaml",
+	".prettierrc.json5",
+	".prettierrc.js",
+	".prettierrc.cjs",
+	".prettierrc.mjs",
+	".prettierrc.toml",
+	"prettier.config.js",
+	"prettier.config.cjs",
+	"prettier.config.mjs",
+}
+
+var stylelintConfigFiles = []string{
+	".stylelintrc",
+	".stylelintrc.json",
+	".stylelintrc.yml",
+	".stylelintrc.yaml",
+	".stylelintrc.js",
+	".stylelintrc.cjs",
+	".stylelintrc.mjs",
+	"stylelint.config.js",
+	"stylelint.config.cjs",
+	"stylelint.config.mjs",
+}
+
+// alwaysSkipDirs are never scanned: they hold dependencies and VCS data,
+// not project configs.
+var alwaysSkipDirs = []string{"node_modules", ".git"}
+
+// defaultSkipDirs are skipped unless -skip-dirs replaces them. .devops is
+// where the deployment tooling of the monorepos this tool was written for
+// keeps copies of project configs; it's harmless to drop elsewhere.
+var defaultSkipDirs = []string{"dist", "build", ".devops"}
+
+const minimalBiomeConfig = `{
+  "linter": {
+    "enabled": true,
+    "rules": {
+      "recommended": true
+    }
+  }
+}
+`
+
+// printer writes the tool's own output. In errors-only mode progress lines
+// are dropped and only failures and warnings get through. With -log-format
+// text or json, messages become log/slog records on w.
+type printer struct {
+	w          io.Writer
+	errorsOnly bool
+	verbose    bool
+	explain    bool
+	progress   *progress
+	// level is the -log-level; warnf output is dropped above warn.
+	level     slog.Level
+	log       *slog.Logger
+	logFormat string
+	// runID is added to every record and, in plain verbose output, in
+	// front of every line.
+	runID string
+	// buffered is set on a worker's printer whose output is held back
+	// until its location finishes.
+	buffered bool
+}
+
+// bufferedTo returns a copy of p that writes to w, for output that is
+// flushed later in one piece.
+func (p *printer) bufferedTo(w io.Writer) *printer {
+	c := *p
+	c.w = w
+	c.log = newLogger(p.logFormat, p.level, w, p.runID)
+	c.progress = nil
+	c.buffered = true
+	return &c
+}
+
+// explainf prints the rationale for an action when -explain is set.
+func (p *printer) explainf(format string, args ...any) {
+	if !p.explain || p.errorsOnly {
+		return
+	}
+	p.emit(slog.LevelDebug, "    why: "+format, args...)
+}
+
+func (p *printer) verbosef(format string, args ...any) {
+	if !p.verbose || p.errorsOnly {
+		return
+	}
+	p.emit(slog.LevelDebug, format, args...)
+}
+
+func (p *printer) infof(format string, args ...any) {
+	if p.errorsOnly {
+		return
+	}
+	p.emit(slog.LevelInfo, format, args...)
+}
+
+func (p *printer) warnf(format string, args ...any) {
+	if p.level > slog.LevelWarn {
+		return
+	}
+	p.emit(slog.LevelWarn, format, args...)
+}
+
+func (p *printer) errorf(format string, args ...any) {
+	p.emit(slog.LevelError, format, args...)
+}
+
+// printf writes the message as is, whatever the log format: for prompts and
+// for output that was already formatted, such as a worker's buffer.
+func (p *printer) printf(format string, args ...any) {
+	p.progress.around(func() []byte {
+		msg := fmt.Sprintf(format, args...)
+		io.WriteString(p.w, msg)
+		return []byte(msg)
+	})
+}
+
+// ConfigLocation is a directory holding ESLint, Prettier or Stylelint
+// configs, as FindConfigs reports it, along with what was detected there.
+type ConfigLocation struct {
+	// Dir is the directory holding the configs.
+	Dir          string
+	HasEslint    bool
+	HasPrettier  bool
+	HasStylelint bool
+	// Root is the input directory the location was found under.
+	Root string
+	// LogicalDir is the path a location is known by when it was reached
+	// through -resolve-aliases; Dir is then the real directory.
+	LogicalDir string
+	// Files holds the paths of the matched config files.
+	Files []string
+	// IgnoreFiles holds the paths of the .eslintignore and .prettierignore
+	// files next to the configs.
+	IgnoreFiles []string
+	// EslintFormats lists the formats of the ESLint configs found: json,
+	// yaml, js or package.json.
+	EslintFormats []string
+	// Symlinks holds the paths of matched config files that are symlinks,
+	// typically to a config shared across a monorepo.
+	Symlinks []string
+	// PrettierDisabled is set when the location's Prettier configs are all
+	// empty or switched off, e.g. "prettier": false in package.json, and
+	// EslintEmpty when its ESLint configs are all {}. Those migrations are
+	// skipped.
+	PrettierDisabled bool
+	EslintEmpty      bool
+
+	// previewOf is set on the temporary copy previewConfig migrates, to the
+	// directory it was copied from.
+	previewOf string
+}
+
+// refDir returns the directory relative references in loc's config are
+// computed from: Dir, or for a preview copy the directory it was copied
+// from.
+func (loc *ConfigLocation) refDir() string {
+	if loc.previewOf != "" {
+		return loc.previewOf
+	}
+	return loc.Dir
+}
+
+// displayDir returns the path to show users: the logical path for aliased
+// locations, along with the real one.
+func (loc *ConfigLocation) displayDir() string {
+	if loc.LogicalDir == "" || loc.LogicalDir == loc.Dir {
+		return loc.Dir
+	}
+	return loc.LogicalDir + " (" + loc.Dir + ")"
+}
+
+// tools lists the config kinds detected at the location.
+func (loc *ConfigLocation) tools() []string {
+	tools := []string{}
+	if loc.HasEslint {
+		tools = append(tools, "eslint")
+	}
+	if loc.HasPrettier {
+		tools = append(tools, "prettier")
+	}
+	if loc.HasStylelint {
+		tools = append(tools, "stylelint")
+	}
+	return tools
+}
+
+// Options holds the settings that affect how each location is migrated,
+// for Migrate and the command line alike.
+type Options struct {
+	DryRun bool
+	// DryRunDiff makes a dry run migrate a temporary copy of each location
+	// and print the diff to the current config.
+	DryRunDiff      bool
+	NoMinimalConfig bool
+	FromPackageRoot bool
+	MigrateJSON     bool
+	// Overlays are deep-merged into every config after the built-in
+	// patches, in order.
+	Overlays     []map[string]any
+	BiomeVersion string
+	LocalSchema  string
+	RunCheck     bool
+	// CompareEslint reports ESLint rules left without a Biome counterpart.
+	CompareEslint bool
+	// Runner is the package runner used to execute Biome; empty means
+	// detected per location.
+	Runner string
+	// Emit is the format of the written config, json or jsonc; empty
+	// means json.
+	Emit string
+	// UseNpx always runs Biome through the package runner, even when a
+	// local biome binary is installed.
+	UseNpx bool
+	// binary is the biome executable run for the current location, when
+	// one was found.
+	binary string
+	// Timeout limits how long each biome command may run; zero means no
+	// limit.
+	Timeout time.Duration
+	// Monorepo makes configs below the scan root extend the root's config
+	// instead of standing alone.
+	Monorepo bool
+	// monorepoRoots holds the scan roots that get a config in this run, so
+	// configs below them can extend it before it is written.
+	monorepoRoots map[string]bool
+	// confirm asks before each location is migrated; nil migrates all.
+	confirm *confirmer
+	// Validate has Biome load each written biome.json before it counts as
+	// migrated.
+	Validate bool
+
+	// Backup copies the files migration touches to .bak files first, all
+	// with the same backupStamp suffix.
+	Backup      bool
+	backupStamp string
+
+	// Force migrates over a biome.json that git tracks, which is otherwise
+	// left alone as a deliberately committed config.
+	Force bool
+
+	// OutputDir, when set, receives the generated configs in a tree that
+	// mirrors the input directory, which is then left untouched.
+	OutputDir string
+	// recordChanges keeps, on each result, the files the location's
+	// migration changed, for the -rollback manifest.
+	recordChanges bool
+	// outputPerRoot puts each input directory's mirror in a subdirectory
+	// of OutputDir, for runs over several of them.
+	outputPerRoot bool
+
+	// Cleanup deletes a location's config files of each tool whose
+	// migration succeeded, once biome.json has been written.
+	Cleanup bool
+
+	// ResolveSharedConfigs warns about package.json "prettier" keys that
+	// name a shareable config package and reports what it resolves to.
+	ResolveSharedConfigs bool
+
+	// OverwriteInvalid replaces an existing biome.json that isn't valid
+	// JSON instead of skipping the location.
+	OverwriteInvalid bool
+
+	// WriteBiomeignore writes a .biomeignore next to each biome.json,
+	// starting from BiomeignoreTemplate.
+	WriteBiomeignore    bool
+	BiomeignoreTemplate []string
+
+	// Output receives what Migrate prints. Nil discards it.
+	Output io.Writer
+
+	// template and jsonPatch are loaded from the -template and
+	// -json-patch files.
+	template  *configTemplate
+	jsonPatch []jsonPatchOp
+
+	// runnerLimits caps the biome commands run through each package
+	// runner at once; nil means no cap.
+	runnerLimits runnerLimits
+
+	// validateSchema checks each proposed biome.json against the Biome
+	// schema in dry-run; schemas caches the schema files read for it.
+	validateSchema bool
+	schemas        *schemaCache
+}
+
+// migrateLocation runs the ESLint/Prettier migrations for a single location
+// and patches the resulting biome.json.
+func migrateLocation(ctx context.Context, loc *ConfigLocation, opts *Options, out *printer) *locationResult {
+	res := &locationResult{loc: loc, disabledTools: loc.disabledTools()}
+	dir := loc.Dir
+
+	if opts.DryRun {
+		out.infof("\n[DRY RUN] Would migrate in: %s\n", loc.displayDir())
+		if opts.ResolveSharedConfigs {
+			reportSharedConfigs(loc, out)
+		}
+		if loc.HasEslint {
+			warnExtendsOnly(loc, out)
+		}
+		if loc.EslintEmpty {
+			out.infof("[DRY RUN]   - eslint: empty, skipped\n")
+		} else if loc.HasEslint {
+			if loc.onlyEslintFormat(formatYAML) {
+				out.infof("[DRY RUN]   - ESLint migration, from %s converted to JSON\n", filepath.Base(loc.eslintYAMLFile()))
+			} else {
+				out.infof("[DRY RUN]   - ESLint migration\n")
+			}
+		}
+		if loc.PrettierDisabled {
+			out.infof("[DRY RUN]   - prettier: disabled, skipped\n")
+		} else if loc.HasPrettier {
+			out.infof("[DRY RUN]   - Prettier migration\n")
+		}
+		if loc.HasStylelint {
+			out.infof("[DRY RUN]   - Stylelint: enable Biome's CSS linter\n")
+		}
+		if opts.NoMinimalConfig {
+			out.infof("[DRY RUN]   - No minimal biome.json, migrate must create it\n")
+		}
+		if opts.Cleanup {
+			for _, tool := range loc.tools() {
+				if slices.Contains(loc.disabledTools(), tool) {
+					continue
+				}
+				for _, path := range toolFiles(loc, tool) {
+					if slices.Contains(keptByCleanup, tool) {
+						out.infof("[DRY RUN]   - Would keep %s, its rules are not carried over to Biome\n", path)
+						continue
+					}
+					out.infof("[DRY RUN]   - Would delete %s\n", path)
+				}
+			}
+		}
+		biomeConfigPath := existingConfigPath(dir, opts)
+		if _, err := os.Stat(biomeConfigPath); err != nil && opts.template != nil && !opts.NoMinimalConfig {
+			if _, err := opts.template.render(loc.refDir()); err != nil {
+				out.errorf("[DRY RUN]   - Template would fail for %s: %v\n", dir, err)
+				res.addError(fmt.Errorf("template: %w", err))
+			}
+		}
+		if _, err := os.Stat(biomeConfigPath); err == nil && !isValidConfigFile(biomeConfigPath) {
+			if opts.OverwriteInvalid {
+				out.infof("[DRY RUN]   - Existing %s is not valid JSON and would be replaced\n", filepath.Base(biomeConfigPath))
+			} else {
+				out.warnf("[DRY RUN]   - Existing %s in %s is not valid JSON and would be skipped\n", filepath.Base(biomeConfigPath), dir)
+			}
+		}
+		if opts.OutputDir == "" && !opts.Force && gitTracked(biomeConfigPath) {
+			out.warnf("[DRY RUN]   - %s is tracked by git and would be skipped (use -force to migrate over it)\n", filepath.Base(biomeConfigPath))
+			res.skipped = true
+			res.tracked = true
+			return res
+		}
+		if opts.validateSchema {
+			validateProposed(res, dir, opts, out)
+		}
+		target := biomeConfigPath
+		if opts.OutputDir != "" {
+			var err error
+			if target, err = outputPath(loc, opts); err != nil {
+				out.errorf("[DRY RUN]   - Error: %v\n", err)
+				res.addError(fmt.Errorf("output: %w", err))
+				return res
+			}
+		}
+		_, err := os.Stat(target)
+		res.created = err != nil
+		if opts.DryRunDiff {
+			res.outOfDate = printConfigDiff(ctx, loc, opts, out, res)
+		} else {
+			for _, tool := range loc.tools() {
+				if !slices.Contains(loc.disabledTools(), tool) {
+					res.succeeded = append(res.succeeded, tool)
+				}
+			}
+			res.outOfDate = wouldChange(biomeConfigPath, loc, legacyIgnorePatterns(loc), opts)
+			if res.outOfDate {
+				out.infof("[DRY RUN]   - %s would be created or changed\n", configFileName(opts))
+			}
+		}
+		if opts.WriteBiomeignore {
+			patterns, err := biomeignorePatterns(dir, opts.BiomeignoreTemplate)
+			if err != nil {
+				out.errorf("Error reading ignore files in %s: %v\n", dir, err)
+			} else {
+				out.infof("[DRY RUN]   - Would write .biomeignore with:\n")
+				for _, p := range patterns {
+					out.infof("[DRY RUN]       %s\n", p)
+				}
+			}
+		}
+		return res
+	}
+
+	if opts.OutputDir != "" {
+		return migrateToOutputDir(ctx, loc, opts, out)
+	}
+	if existing := existingConfigPath(dir, opts); !opts.Force && gitTracked(existing) {
+		out.warnf("Warning: %s is tracked by git; skipping so the committed config isn't rewritten (use -force to migrate over it)\n", existing)
+		res.skipped = true
+		res.tracked = true
+		return res
+	}
+	if opts.recordChanges {
+		before := snapshotFiles(watchedFiles(loc, opts))
+		defer func() { res.changes = changesSince(before) }()
+	}
+
+	out.verbosef("\nMigrating: %s\n", loc.displayDir())
+	if opts.ResolveSharedConfigs {
+		reportSharedConfigs(loc, out)
+	}
+	if loc.HasEslint {
+		warnExtendsOnly(loc, out)
+	}
+
+	// The runner is settled before any file is touched, so a location
+	// whose runner is ambiguous is left as it was.
+	opts, conflict := biomeFor(loc, opts)
+	if len(conflict) > 0 {
+		out.errorf("Error: lockfiles of several package managers found for %s; runner detection is ambiguous, so pass -runner to choose one:\n", dir)
+		for _, lockfile := range conflict {
+			out.errorf("  - %s\n", lockfile)
+		}
+		res.lockfileConflict = conflict
+		res.addError(errAmbiguousRunner)
+		return res
+	}
+	if opts.binary != "" {
+		out.verbosef("  Using Biome binary %s\n", opts.binary)
+	} else {
+		out.verbosef("  Using runner %s\n", opts.Runner)
+	}
+
+	biomeConfigPath := filepath.Join(dir, configFileName(opts))
+	existingBiome := false
+	if _, err := os.Stat(biomeConfigPath); err == nil {
+		existingBiome = true
+	}
+
+	// restoreInvalid undoes the migration from the backups when it left a
+	// config that doesn't parse, and reports whether it did.
+	restoreInvalid := func() bool { return false }
+	if opts.Backup {
+		entries, err := backupLocation(dir, opts.backupStamp)
+		for _, e := range entries {
+			out.infof("  Backup: %s\n", e.copy)
+		}
+		if err != nil {
+			out.errorf("Error backing up files in %s: %v\n", dir, err)
+			res.addError(fmt.Errorf("backup: %w", err))
+			return res
+		}
+		// The config is only created by this run if it didn't exist before
+		// the backup, even when a legacy config is renamed to it below.
+		restore := &pendingRestore{configPath: biomeConfigPath, existed: existingBiome, entries: entries}
+		restored := false
+		restoreInvalid = func() bool {
+			if err := restoreBackups(restore.configPath, restore.existed, restore.entries, out); err != nil {
+				out.errorf("Error restoring the backups of %s: %v\n", dir, err)
+				return false
+			}
+			restored = true
+			return true
+		}
+		defer func() {
+			if res.failed() && !restored {
+				res.restore = restore
+			}
+		}()
+	}
+
+	if legacy := existingConfigPath(dir, opts); legacy != biomeConfigPath {
+		if err := os.Rename(legacy, biomeConfigPath); err != nil {
+			out.errorf("Error renaming %s to %s: %v\n", legacy, filepath.Base(biomeConfigPath), err)
+			res.addError(err)
+			return res
+		}
+		out.verbosef("  Renamed %s to %s\n", legacy, filepath.Base(biomeConfigPath))
+		existingBiome = true
+	}
+
+	if existingBiome && !isValidConfigFile(biomeConfigPath) {
+		if !opts.OverwriteInvalid {
+			out.warnf("Warning: existing %s is not valid JSON; skipping (use -overwrite-invalid to replace it)\n", biomeConfigPath)
+			res.addError(errInvalidBiomeConfig)
+			return res
+		}
+		out.warnf("Warning: replacing invalid %s with a freshly migrated config\n", biomeConfigPath)
+		if err := os.Remove(biomeConfigPath); err != nil {
+			out.errorf("Error removing invalid %s: %v\n", biomeConfigPath, err)
+			res.addError(err)
+			return res
+		}
+		existingBiome = false
+	}
+
+	var originalConfig map[string]any
+	if existingBiome {
+		if data, err := os.ReadFile(biomeConfigPath); err == nil {
+			decodeConfig(data, isJSONC(biomeConfigPath), &originalConfig)
+		}
+	}
+
+	switch {
+	case existingBiome:
+		out.explainf("%s already exists, so it is migrated into and patched in place\n", configFileName(opts))
+	case opts.NoMinimalConfig:
+		out.explainf("-no-minimal-config is set, so biome migrate has to create %s itself\n", configFileName(opts))
+	default:
+		out.explainf("no %s yet, so a minimal one is written for biome migrate to fill in\n", configFileName(opts))
+	}
+
+	if !existingBiome && !opts.NoMinimalConfig {
+		initial, err := initialConfig(loc.refDir(), opts)
+		if err != nil {
+			out.errorf("Error rendering template for %s: %v\n", dir, err)
+			res.addError(fmt.Errorf("template: %w", err))
+			return res
+		}
+		if err := os.WriteFile(biomeConfigPath, initial, 0o644); err != nil {
+			out.errorf("Error creating %s: %v\n", biomeConfigPath, err)
+			res.addError(err)
+			return res
+		}
+	}
+
+	workDir := dir
+	if opts.FromPackageRoot {
+		workDir = packageRoot(dir)
+	}
+	out.verbosef("  Running biome migrate from %s\n", workDir)
+	if workDir != dir {
+		out.warnf("Warning: biome migrate reads the ESLint and Prettier configs of %s, not those in %s, as it has no option naming the configs to migrate\n", workDir, dir)
+	}
+
+	if loc.EslintEmpty {
+		out.verbosef("  eslint: empty, skipped\n")
+		out.explainf("every ESLint config here is {}, so there are no rules to migrate\n")
+	} else if loc.HasEslint {
+		migrated, err := migrateEslintConfig(ctx, loc, workDir, opts, out)
+		res.setMigrateOutput("eslint", migrated)
+		if err != nil {
+			out.errorf("Error migrating ESLint config in %s: %v\n", dir, err)
+			res.addError(fmt.Errorf("eslint migration: %w", err))
+			res.failedTools = append(res.failedTools, "eslint")
+		} else {
+			res.succeeded = append(res.succeeded, "eslint")
+			out.verbosef("  ✓ ESLint migrated\n")
+			out.explainf("an ESLint config was found here, so biome migrate eslint ran\n")
+		}
+	} else {
+		out.explainf("ESLint migration skipped, there is no ESLint config here\n")
+	}
+
+	if loc.PrettierDisabled {
+		out.verbosef("  prettier: disabled, skipped\n")
+		out.explainf("the Prettier config here is empty or false, so its settings shouldn't become Biome formatter options\n")
+	} else if loc.HasPrettier {
+		migrated, err := migratePrettierConfig(ctx, dir, workDir, opts, out)
+		res.setMigrateOutput("prettier", migrated)
+		if err != nil {
+			out.errorf("Error migrating Prettier config in %s: %v\n", dir, err)
+			res.addError(fmt.Errorf("prettier migration: %w", err))
+			res.failedTools = append(res.failedTools, "prettier")
+		} else {
+			res.succeeded = append(res.succeeded, "prettier")
+			out.verbosef("  ✓ Prettier migrated\n")
+			out.explainf("a Prettier config was found here, so biome migrate prettier ran\n")
+		}
+	} else {
+		out.explainf("Prettier migration skipped, there is no Prettier config here\n")
+	}
+
+	if loc.HasStylelint {
+		if err := migrateStylelintConfig(dir, opts); err != nil {
+			out.errorf("Error migrating Stylelint config in %s: %v\n", dir, err)
+			res.addError(fmt.Errorf("stylelint migration: %w", err))
+			res.failedTools = append(res.failedTools, "stylelint")
+		} else {
+			res.succeeded = append(res.succeeded, "stylelint")
+			out.verbosef("  ✓ Stylelint: enabled Biome's CSS linter (rules are not carried over)\n")
+			out.explainf("a Stylelint config was found here; Biome has no stylelint migration, so only CSS linting was switched on\n")
+		}
+	}
+
+	if len(res.failedTools) > 0 && !existingBiome && !loc.HasEslint && !loc.HasPrettier && !loc.HasStylelint {
+		os.Remove(biomeConfigPath)
+		return res
+	}
+
+	// An interrupted location isn't patched. A config written by this run
+	// holds at most the minimal one, so it is removed again.
+	if ctx.Err() != nil {
+		if !res.failed() {
+			res.addError(errInterrupted)
+		}
+		if !existingBiome {
+			if err := os.Remove(biomeConfigPath); err == nil {
+				out.errorf("Interrupted, removed %s\n", biomeConfigPath)
+			}
+		}
+		return res
+	}
+
+	if _, err := os.Stat(biomeConfigPath); err != nil {
+		out.errorf("Error: biome migrate did not produce %s\n", biomeConfigPath)
+		res.addError(fmt.Errorf("biome migrate did not produce %s", configFileName(opts)))
+		res.noConfig = true
+		return res
+	}
+
+	if err := patchBiomeConfig(biomeConfigPath, loc, originalConfig, legacyIgnorePatterns(loc), opts); errors.Is(err, errInvalidBiomeConfig) {
+		res.addError(err)
+		if restoreInvalid() {
+			out.warnf("Warning: %s was not valid JSON after migrating, so the location was put back as it was before (%v)\n", biomeConfigPath, err)
+			return res
+		}
+		out.warnf("Warning: %s is not valid JSON and was left unpatched; inspect it manually (%v)\n", biomeConfigPath, err)
+		return res
+	} else if err != nil {
+		out.errorf("Error patching %s: %v\n", biomeConfigPath, err)
+		res.addError(fmt.Errorf("patch: %w", err))
+	}
+
+	if err := verifyConfigFile(biomeConfigPath); err != nil {
+		out.errorf("Error: %s failed to reparse after writing: %v\n", biomeConfigPath, err)
+		res.addError(err)
+		res.reparseFailed = true
+		if restoreInvalid() {
+			out.warnf("Warning: %s was put back as it was before migrating\n", dir)
+		}
+		return res
+	}
+
+	if opts.Validate {
+		if output, err := validateConfig(ctx, dir, opts, out); errors.Is(err, errConfigRejected) {
+			out.errorf("Error: Biome rejected %s:\n%s\n", biomeConfigPath, output)
+			res.addError(err)
+			return res
+		} else if err != nil {
+			out.errorf("Error validating %s: %v\n", biomeConfigPath, err)
+			res.addError(fmt.Errorf("validate: %w", err))
+			return res
+		}
+	}
+
+	res.migrated = true
+	res.created = !existingBiome
+	if res.failed() {
+		out.errorf("Wrote %s, but the migration above failed\n", biomeConfigPath)
+	} else {
+		out.verbosef("Created: %s\n", biomeConfigPath)
+	}
+	explainPatches(out, opts)
+
+	if opts.CompareEslint && loc.HasEslint {
+		cmp, err := compareEslintRules(loc, biomeConfigPath)
+		if err != nil {
+			out.warnf("Warning: could not compare ESLint rules in %s: %v\n", dir, err)
+		} else {
+			res.eslintComparison = cmp
+			printComparison(out, cmp)
+		}
+	}
+
+	if opts.RunCheck {
+		counts, err := countDiagnostics(ctx, dir, opts, out)
+		if err != nil {
+			out.warnf("Warning: could not count Biome diagnostics in %s: %v\n", dir, err)
+		} else {
+			res.diagnostics = &counts
+			out.infof("  Biome check: %d error(s), %d warning(s)\n", counts.errors, counts.warnings)
+		}
+	}
+
+	if opts.WriteBiomeignore {
+		patterns, err := biomeignorePatterns(dir, opts.BiomeignoreTemplate)
+		if err == nil {
+			err = writeBiomeignore(dir, patterns)
+		}
+		if err != nil {
+			out.errorf("Error writing .biomeignore in %s: %v\n", dir, err)
+			res.addError(fmt.Errorf("biomeignore: %w", err))
+		} else {
+			out.infof("Created: %s\n", filepath.Join(dir, ".biomeignore"))
+		}
+	}
+
+	// Last, so the steps above can still read the old configs.
+	if opts.Cleanup {
+		if err := cleanupConfigs(loc, res.succeeded, out); err != nil {
+			out.errorf("Error removing old configs in %s: %v\n", dir, err)
+			res.addError(fmt.Errorf("cleanup: %w", err))
+		}
+	}
+	return res
+}
+
+// printByTool prints the detected locations grouped by config kind.
+func printByTool(out *printer, locations map[string]*ConfigLocation, dirs []string) {
+	var eslint, prettier, stylelint []string
+	for _, dir := range dirs {
+		if locations[dir].HasEslint {
+			eslint = append(eslint, dir)
+		}
+		if locations[dir].HasPrettier {
+			prettier = append(prettier, dir)
+		}
+		if locations[dir].HasStylelint {
+			stylelint = append(stylelint, dir)
+		}
+	}
+
+	for _, group := range []struct {
+		name string
+		dirs []string
+	}{{"ESLint", eslint}, {"Prettier", prettier}, {"Stylelint", stylelint}} {
+		out.infof("\n%s (%d):\n", group.name, len(group.dirs))
+		for _, dir := range group.dirs {
+			out.infof("  - %s\n", dir)
+		}
+	}
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// explainPatches describes why patchConfig set each key.
+func explainPatches(out *printer, opts *Options) {
+	out.explainf("formatter.formatWithErrors set, unless already configured, so Biome still formats files with syntax errors\n")
+	out.explainf("javascript.parser.unsafeParameterDecoratorsEnabled set, unless already configured, so TypeScript parameter decorators parse\n")
+	if opts.LocalSchema != "" {
+		out.explainf("$schema points at %s because -local-schema is set\n", opts.LocalSchema)
+	} else if opts.BiomeVersion != "" {
+		out.explainf("$schema points at the %s schema because -biome-version pins it\n", opts.BiomeVersion)
+	}
+	if len(opts.Overlays) > 0 {
+		out.explainf("%d overlay(s) merged on top because -overlay was given\n", len(opts.Overlays))
+	}
+	if len(opts.jsonPatch) > 0 {
+		out.explainf("%d JSON Patch operation(s) applied last because -json-patch was given\n", len(opts.jsonPatch))
+	}
+}
+
+// baseNames returns the last element of each path.
+func baseNames(paths []string) []string {
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = filepath.Base(path)
+	}
+	return names
+}
+
+// inputRoots resolves the directories to scan: each -input, comma lists
+// split, followed by each NUL-delimited path read from -input-list0. Inputs
+// that are, or are inside, another input after resolving symlinks are
+// returned as overlapping instead.
+func inputRoots(inputs []string, list0 string) (roots, overlapping []string, err error) {
+	var paths []string
+	for _, input := range inputs {
+		paths = append(paths, splitList(input)...)
+	}
+
+	if list0 != "" {
+		var data []byte
+		if list0 == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(list0)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		// Entries are taken verbatim: a newline is a valid character of a
+		// NUL-delimited path.
+		for _, path := range strings.Split(string(data), "\x00") {
+			if path != "" {
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	if len(paths) == 0 {
+		return nil, nil, errors.New("no input directories given")
+	}
+
+	// resolvedRoots holds the symlink-resolved path of each kept root, so
+	// roots that are the same directory or nested in one another are only
+	// scanned once, through the outermost.
+	var resolvedRoots []string
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		resolved, err := filepath.EvalSymlinks(abs)
+		if err != nil {
+			return nil, nil, err
+		}
+		if slices.ContainsFunc(resolvedRoots, func(r string) bool { return within(resolved, r) }) {
+			overlapping = append(overlapping, abs)
+			continue
+		}
+		for i := len(resolvedRoots) - 1; i >= 0; i-- {
+			if within(resolvedRoots[i], resolved) {
+				overlapping = append(overlapping, roots[i])
+				roots = slices.Delete(roots, i, i+1)
+				resolvedRoots = slices.Delete(resolvedRoots, i, i+1)
+			}
+		}
+		roots = append(roots, abs)
+		resolvedRoots = append(resolvedRoots, resolved)
+	}
+	return roots, overlapping, nil
+}
+
+// within reports whether path is dir or inside it.
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// sortedDirs returns the location directories in lexical order so output
+// and reports are stable between runs.
+func sortedDirs(locations map[string]*ConfigLocation) []string {
+	dirs := make([]string, 0, len(locations))
+	for dir := range locations {
+		dirs = append(dirs, dir)
+	}
+	slices.Sort(dirs)
+	return dirs
+}
+
+// scanOptions narrows down which files findConfigs considers.
+type scanOptions struct {
+	// tracked holds the absolute paths of git-tracked files. When non-nil,
+	// config files missing from it are ignored.
+	tracked map[string]bool
+	// skipDirs names directories to skip in addition to alwaysSkipDirs.
+	skipDirs []string
+	// skipSymlinks ignores config files that are symlinks.
+	skipSymlinks bool
+	// includeHidden scans directories whose name starts with a dot, such
+	// as .yarn or .cache, which are skipped otherwise.
+	includeHidden bool
+	// gitignore skips paths excluded by .gitignore files, from the top of
+	// the work tree down, each applying relative to its own directory.
+	gitignore bool
+	// ignore holds -ignore patterns, in .gitignore syntax relative to the
+	// scanned root.
+	ignore []string
+}
+
+func findConfigs(root string, opts scanOptions) (map[string]*ConfigLocation, error) {
+	locations := make(map[string]*ConfigLocation)
+
+	var baseRules []ignoreRule
+	for _, pattern := range opts.ignore {
+		if rule, ok := parseIgnoreRule(root, pattern); ok {
+			baseRules = append(baseRules, rule)
+		}
+	}
+	if opts.gitignore {
+		ancestors, _ := ancestorGitignores(root)
+		baseRules = append(ancestors, baseRules...)
+	}
+	// rules holds the ignore rules in effect inside each visited directory.
+	rules := map[string][]ignoreRule{filepath.Dir(root): baseRules}
+	// ignoreFiles holds the legacy ignore files per directory; they only
+	// matter where configs are found too.
+	ignoreFiles := make(map[string][]string)
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsPermission(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+
+		inherited := rules[filepath.Dir(path)]
+		if info.IsDir() {
+			// Skip rules match directories below the input root only. The
+			// root is an absolute path, so a relative -input like "." would
+			// otherwise be skipped for the name of the working directory.
+			name := info.Name()
+			if path != root && (slices.Contains(alwaysSkipDirs, name) || slices.Contains(opts.skipDirs, name)) {
+				return filepath.SkipDir
+			}
+			if path != root && strings.HasPrefix(name, ".") && !opts.includeHidden {
+				return filepath.SkipDir
+			}
+			if path != root && ignored(inherited, path, true) {
+				return filepath.SkipDir
+			}
+			dirRules := inherited
+			if opts.gitignore {
+				own, _ := readGitignore(path)
+				dirRules = append(slices.Clip(inherited), own...)
+			}
+			rules[path] = dirRules
+			return nil
+		}
+
+		if ignored(inherited, path, false) {
+			return nil
+		}
+
+		if slices.Contains(legacyIgnoreFiles, info.Name()) {
+			ignoreFiles[filepath.Dir(path)] = append(ignoreFiles[filepath.Dir(path)], path)
+			return nil
+		}
+
+		if opts.tracked != nil && !opts.tracked[path] {
+			return nil
+		}
+
+		fileName := info.Name()
+		dir := filepath.Dir(path)
+
+		isConfig := slices.Contains(eslintConfigFiles, fileName) || slices.Contains(prettierConfigFiles, fileName) ||
+			slices.Contains(stylelintConfigFiles, fileName)
+		if isConfig && info.Mode()&os.ModeSymlink != 0 {
+			if opts.skipSymlinks {
+				return nil
+			}
+			if locations[dir] == nil {
+				locations[dir] = &ConfigLocation{Dir: dir, Root: root}
+			}
+			locations[dir].Symlinks = append(locations[dir].Symlinks, path)
+		}
+
+		if isConfig {
+			if locations[dir] == nil {
+				locations[dir] = &ConfigLocation{Dir: dir, Root: root}
+			}
+			locations[dir].Files = append(locations[dir].Files, path)
+		}
+
+		if fileName == "package.json" {
+			hasEslint, hasPrettier := embeddedConfigs(path)
+			if hasEslint || hasPrettier {
+				if locations[dir] == nil {
+					locations[dir] = &ConfigLocation{Dir: dir, Root: root}
+				}
+				loc := locations[dir]
+				loc.Files = append(loc.Files, path)
+				loc.HasEslint = loc.HasEslint || hasEslint
+				loc.HasPrettier = loc.HasPrettier || hasPrettier
+				if hasEslint {
+					loc.addFormat(formatPackageJSON)
+				}
+			}
+		}
+
+		if slices.Contains(eslintConfigFiles, fileName) {
+			if locations[dir] == nil {
+				locations[dir] = &ConfigLocation{Dir: dir, Root: root}
+			}
+			locations[dir].HasEslint = true
+			locations[dir].addFormat(eslintFormat(path))
+		}
+
+		if slices.Contains(prettierConfigFiles, fileName) {
+			if locations[dir] == nil {
+				locations[dir] = &ConfigLocation{Dir: dir, Root: root}
+			}
+			locations[dir].HasPrettier = true
+		}
+
+		if slices.Contains(stylelintConfigFiles, fileName) {
+			if locations[dir] == nil {
+				locations[dir] = &ConfigLocation{Dir: dir, Root: root}
+			}
+			locations[dir].HasStylelint = true
+		}
+
+		return nil
+	})
+
+	for dir, paths := range ignoreFiles {
+		if loc := locations[dir]; loc != nil {
+			loc.IgnoreFiles = paths
+		}
+	}
+	for _, loc := range locations {
+		markDisabledConfigs(loc)
+	}
+	return locations, err
+}
+
+// gitTrackedFiles lists the files git tracks under root, keyed by absolute
+// path. It fails when root is not inside a git work tree.
+func gitTrackedFiles(root string) (map[string]bool, error) {
+	cmd := exec.Command("git", "ls-files", "-z")
+	cmd.Dir = root
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	tracked := make(map[string]bool)
+	for _, name := range strings.Split(string(output), "\x00") {
+		if name == "" {
+			continue
+		}
+		tracked[filepath.Join(root, filepath.FromSlash(name))] = true
+	}
+	return tracked, nil
+}
+
+// gitTracked reports whether path exists and git tracks it. Outside a git
+// work tree, or without git installed, the file counts as untracked.
+func gitTracked(path string) bool {
+	if _, err := os.Stat(path); err != nil {
+		return false
+	}
+	cmd := exec.Command("git", "ls-files", "--error-unmatch", filepath.Base(path))
+	cmd.Dir = filepath.Dir(path)
+	return cmd.Run() == nil
+}
+
+// findInstalledBiome looks for a Biome that npx can use without downloading:
+// a local node_modules/.bin/biome in root or any parent, a biome binary on
+// PATH, or a copy in the npx cache. It returns a description of where it was
+// found.
+func findInstalledBiome(root string) (string, bool) {
+	for dir := root; ; dir = filepath.Dir(dir) {
+		bin := filepath.Join(dir, "node_modules", ".bin", "biome")
+		if _, err := os.Stat(bin); err == nil {
+			return "local install at " + bin, true
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	if path, err := exec.LookPath("biome"); err == nil {
+		return "on PATH at " + path, true
+	}
+
+	cacheDir := os.Getenv("npm_config_cache")
+	if cacheDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		cacheDir = filepath.Join(home, ".npm")
+	}
+	matches, _ := filepath.Glob(filepath.Join(cacheDir, "_npx", "*", "node_modules", "@biomejs", "biome", "package.json"))
+	if len(matches) > 0 {
+		return "npx cache at " + filepath.Dir(matches[0]), true
+	}
+
+	return "", false
+}
+
+// migrateEslintConfig runs biome migrate eslint for loc. biome migrate can't
+// read YAML, so a location with only YAML configs gets a temporary JSON copy
+// for the duration of the command. A failure on JavaScript configs, which
+// Biome needs Node.js to load, is reported as an unsupported format.
+func migrateEslintConfig(ctx context.Context, loc *ConfigLocation, workDir string, opts *Options, out *printer) (*migrateOutput, error) {
+	if loc.onlyEslintFormat(formatYAML) {
+		converted, err := convertYAMLEslintConfig(loc)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(converted)
+		out.verbosef("  Converted %s to a temporary %s for biome migrate\n", loc.eslintYAMLFile(), convertedEslintName)
+	}
+
+	migrated, err := runMigrate(ctx, "eslint", loc.Dir, workDir, opts, out)
+	if err != nil && loc.onlyEslintFormat(formatJS) && ctx.Err() == nil && !errors.Is(err, errTimedOut) {
+		return migrated, fmt.Errorf("%w: biome migrate could not load the JavaScript config (it needs Node.js to evaluate it): %v", errUnsupportedFormat, err)
+	}
+	return migrated, err
+}
+
+func migratePrettierConfig(ctx context.Context, dir, workDir string, opts *Options, out *printer) (*migrateOutput, error) {
+	return runMigrate(ctx, "prettier", dir, workDir, opts, out)
+}
+
+// migrateStylelintConfig enables Biome's CSS linter in dir's Biome config.
+// Biome has no migrate command for Stylelint, so its rules aren't carried
+// over. A missing config is created.
+func migrateStylelintConfig(dir string, opts *Options) error {
+	path := filepath.Join(dir, configFileName(opts))
+	config := map[string]any{}
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if err := decodeConfig(data, isJSONC(path), &config); err != nil {
+			return fmt.Errorf("%w: %v", errInvalidBiomeConfig, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	css, _ := config["css"].(map[string]any)
+	if css == nil {
+		css = map[string]any{}
+	}
+	linter, _ := css["linter"].(map[string]any)
+	if linter == nil {
+		linter = map[string]any{}
+	}
+	linter["enabled"] = true
+	css["linter"] = linter
+	config["css"] = css
+
+	updated, err := encodeConfig(config, opts)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, updated, 0o644)
+}
+
+// migrateOutput holds what biome migrate printed when -migrate-json-reporter
+// is set: the decoded JSON reporter output, or the plain text when the
+// installed Biome has no JSON reporter for migrate.
+type migrateOutput struct {
+	JSON any    `json:"json,omitempty"`
+	Text string `json:"text,omitempty"`
+}
+
+// runMigrate runs biome migrate for tool. Without -migrate-json-reporter the
+// output goes straight to the terminal and no migrateOutput is returned.
+func runMigrate(ctx context.Context, tool, dir, workDir string, opts *Options, out *printer) (*migrateOutput, error) {
+	args := migrateArgs(tool, dir, workDir)
+	if !opts.MigrateJSON {
+		return nil, runBiome(ctx, workDir, opts, out, args...)
+	}
+
+	output, err := runBiomeCapture(ctx, workDir, opts, out, append(args, "--reporter=json")...)
+	if err != nil && bytes.Contains(bytes.ToLower(output), []byte("reporter")) {
+		out.verbosef("  biome migrate has no JSON reporter, capturing plain output\n")
+		output, err = runBiomeCapture(ctx, workDir, opts, out, args...)
+		if err != nil {
+			out.childOutput(output)
+		}
+		return &migrateOutput{Text: string(output)}, err
+	}
+	if err != nil {
+		out.childOutput(output)
+		return &migrateOutput{Text: string(output)}, err
+	}
+
+	var decoded any
+	if jsonErr := json.Unmarshal(output, &decoded); jsonErr != nil {
+		return &migrateOutput{Text: string(output)}, nil
+	}
+	out.verbosef("  biome migrate %s reported: %s\n", tool, bytes.TrimSpace(output))
+	return &migrateOutput{JSON: decoded}, nil
+}
+
+// migrateArgs builds the biome migrate arguments for tool. When migrate runs
+// from a different working directory, --config-path keeps it pointed at the
+// biome.json in dir. Nothing points it at dir's ESLint or Prettier configs:
+// biome migrate always reads those from its working directory.
+func migrateArgs(tool, dir, workDir string) []string {
+	args := []string{"migrate", tool, "--write"}
+	if workDir != dir {
+		args = append(args, "--config-path", dir)
+	}
+	return args
+}
+
+// packageRoot returns the nearest directory at or above dir that contains a
+// package.json, or dir itself when there is none.
+func packageRoot(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "package.json")); err == nil {
+			return d
+		}
+		if filepath.Dir(d) == d {
+			return dir
+		}
+	}
+}
+
+// biomePackage returns the npm package spec the runner executes, pinned to
+// -biome-version when set.
+func biomePackage(opts *Options) string {
+	if opts.BiomeVersion == "" {
+		return "@biomejs/biome"
+	}
+	return "@biomejs/biome@" + opts.BiomeVersion
+}
+
+// biomeVersionPattern matches the -biome-version values accepted, such as
+// 1.9.4 or 2.0.0-beta.1. The value ends up in the runner's arguments and the
+// schema URL, so anything else is rejected.
+var biomeVersionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+
+// biomeCommandLine describes how Biome will be invoked, for the run header.
+func biomeCommandLine(opts *Options) string {
+	runner := opts.Runner
+	if runner == runnerAuto {
+		runner = runnerNpx
+	}
+	name, args := runnerCommand(runner, biomePackage(opts))
+	line := strings.Join(append([]string{name}, args...), " ")
+	if opts.Runner == runnerAuto {
+		line += " (runner detected per location)"
+	}
+	if useLocalBiome(opts) {
+		line = "node_modules/.bin/biome or biome on PATH, else " + line
+	}
+	return line
+}
+
+// commandWaitDelay bounds how long a killed command's output is still
+// read. npx leaves Biome running in a child process that keeps the pipes
+// open after npx itself is killed.
+const commandWaitDelay = 2 * time.Second
+
+// Errors recorded for biome commands that were stopped before finishing.
+var (
+	errTimedOut    = errors.New("timed out")
+	errInterrupted = errors.New("interrupted")
+)
+
+// biomeCommand builds the invocation of a biome subcommand in dir: the local
+// biome binary if one was found, otherwise the runner's, which first waits
+// for a free slot of the runner's -runner-concurrency limit. If ctx is done
+// before a slot frees up, no command is built and the error is
+// errInterrupted, or ctx's error when it wasn't cancelled. The command is
+// killed when ctx is done or, with -timeout, once it has run that long. Pass
+// the command's error to done once it has finished; done frees the slot,
+// releases the timer and reports a killed command as errTimedOut or
+// errInterrupted.
+func biomeCommand(ctx context.Context, dir string, opts *Options, args ...string) (cmd *exec.Cmd, done func(error) error, err error) {
+	name, runnerArgs := runnerCommand(opts.Runner, biomePackage(opts))
+	release := func() {}
+	if opts.binary != "" {
+		name, runnerArgs = opts.binary, nil
+	} else if release, err = opts.runnerLimits.acquire(ctx, opts.Runner); errors.Is(err, context.Canceled) {
+		return nil, nil, errInterrupted
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	cmdCtx, cancel := ctx, context.CancelFunc(func() {})
+	if opts.Timeout > 0 {
+		cmdCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+	}
+	cmd = exec.CommandContext(cmdCtx, name, append(runnerArgs, args...)...)
+	cmd.Dir = dir
+	cmd.WaitDelay = commandWaitDelay
+
+	return cmd, func(err error) error {
+		defer release()
+		defer cancel()
+		switch {
+		case err == nil || cmdCtx.Err() == nil:
+			return err
+		case errors.Is(ctx.Err(), context.Canceled):
+			return errInterrupted
+		default:
+			return fmt.Errorf("biome %s %w after %s", args[0], errTimedOut, opts.Timeout)
+		}
+	}, nil
+}
+
+// runBiomeCapture runs a biome subcommand in dir and returns its standard
+// output, with standard error appended when the command fails.
+func runBiomeCapture(ctx context.Context, dir string, opts *Options, out *printer, args ...string) ([]byte, error) {
+	cmd, done, err := biomeCommand(ctx, dir, opts, args...)
+	if err != nil {
+		return nil, err
+	}
+	out.verbosef("  $ %s\n", strings.Join(cmd.Args, " "))
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := done(cmd.Run()); err != nil {
+		return append(stdout.Bytes(), stderr.Bytes()...), err
+	}
+	return stdout.Bytes(), nil
+}
+
+// runBiome runs a biome subcommand in dir. Its output is only passed through
+// with -v; otherwise it is held back and shown if the command fails.
+func runBiome(ctx context.Context, dir string, opts *Options, out *printer, args ...string) error {
+	cmd, done, err := biomeCommand(ctx, dir, opts, args...)
+	if err != nil {
+		return err
+	}
+	out.verbosef("  $ %s\n", strings.Join(cmd.Args, " "))
+
+	if out.verbose && !out.errorsOnly {
+		cmd.Stdout = out.childWriter()
+		cmd.Stderr = os.Stderr
+		if out.buffered && out.log == nil {
+			cmd.Stderr = out.w
+		}
+		if out.progress != nil {
+			cmd.Stdout = progressWriter{out.progress, out.childWriter()}
+			cmd.Stderr = progressWriter{out.progress, os.Stderr}
+		}
+		return done(cmd.Run())
+	}
+
+	var buf bytes.Buffer
+	cmd.Stdout = &buf
+	cmd.Stderr = &buf
+	if err := done(cmd.Run()); err != nil {
+		out.childOutput(buf.Bytes())
+		return err
+	}
+	return nil
+}
+
+// wouldChange reports whether a real run would create the biome.json at
+// path or change its contents. A config the migrate commands would rewrite
+// can't be predicted, so an existing file only counts as changed when the
+// patches and overlays alter it.
+func wouldChange(path string, loc *ConfigLocation, ignorePatterns []string, opts *Options) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return true
+	}
+	patched, err := patchConfig(data, nil, ignorePatterns, loc.refDir(), loc.Root, opts)
+	return err != nil || !bytes.Equal(patched, data)
+}
+
+// isValidConfigFile reports whether the file at path can be read and holds
+// valid JSON, or JSON with comments for a biome.jsonc.
+func isValidConfigFile(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var config any
+	return decodeConfig(data, isJSONC(path), &config) == nil
+}
+
+// schemaURL returns the published configuration schema for a Biome version.
+func schemaURL(version string) string {
+	return "https://biomejs.dev/schemas/" + version + "/schema.json"
+}
+
+// localSchemaRef returns the $schema reference from a config in dir to the
+// local schema file at schema, relative when possible so the config keeps
+// working when the tree is moved.
+func localSchemaRef(dir, schema string) string {
+	rel, err := filepath.Rel(dir, schema)
+	if err != nil {
+		return filepath.ToSlash(schema)
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.HasPrefix(rel, "../") {
+		rel = "./" + rel
+	}
+	return rel
+}
+
+// verifyConfigFile re-reads a biome.json the tool just wrote and checks that
+// it still parses as a JSON object, so a serialization or encoding bug can't
+// leave a broken file behind unnoticed.
+func verifyConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errReparseFailed, err)
+	}
+	var config map[string]any
+	if err := decodeConfig(data, isJSONC(path), &config); err != nil {
+		return fmt.Errorf("%w: %v", errReparseFailed, err)
+	}
+	return nil
+}
+
+// errReparseFailed marks a biome.json that no longer parses after it was
+// written.
+var errReparseFailed = errors.New("written biome.json does not reparse")
+
+// defaultPatch holds the settings every generated biome.json gets unless it
+// already sets them. It is a patch document like a -patch file, which is
+// merged after it and so can change or, with null, remove any of them.
+const defaultPatch = `{
+  "formatter": {
+    "formatWithErrors": true
+  },
+  "javascript": {
+    "parser": {
+      "unsafeParameterDecoratorsEnabled": true
+    }
+  }
+}`
+
+// builtinPatches returns a fresh copy of defaultPatch.
+func builtinPatches() map[string]any {
+	var patch map[string]any
+	if err := json.Unmarshal([]byte(defaultPatch), &patch); err != nil {
+		panic("migrate: invalid defaultPatch: " + err.Error())
+	}
+	return patch
+}
+
+// errInvalidBiomeConfig is returned by patchBiomeConfig when the file it was
+// asked to patch isn't valid JSON. The file is left exactly as it was.
+var errInvalidBiomeConfig = errors.New("biome.json is not valid JSON")
+
+// patchBiomeConfig applies the built-in patches to loc's config at path and
+// then deep-merges each overlay in order, so later overlays win. original is
+// the config as it was before biome migrate ran, or nil if there was none;
+// sections migrate dropped from it are put back. ignorePatterns are added to
+// files.ignore.
+func patchBiomeConfig(path string, loc *ConfigLocation, original map[string]any, ignorePatterns []string, opts *Options) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	output, err := patchConfig(data, original, ignorePatterns, loc.refDir(), loc.Root, opts)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, output, 0o644)
+}
+
+// patchConfig returns data, the biome.json of dir, with the keys of original
+// it lacks restored, the built-in patches added where not already set,
+// ignorePatterns merged into files.ignore, the $schema reference, the
+// overlays and finally the -json-patch operations applied. With -monorepo,
+// a config below the scan root extends the root's one and leaves the
+// built-in patches to it.
+func patchConfig(data []byte, original map[string]any, ignorePatterns []string, dir, root string, opts *Options) ([]byte, error) {
+	var config map[string]any
+	if err := decodeConfig(data, opts.Emit == emitJSONC, &config); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidBiomeConfig, err)
+	}
+
+	if config == nil {
+		config = map[string]any{}
+	}
+	if original != nil {
+		mergeMissing(config, original)
+	}
+	if ref := rootConfigRef(dir, root, opts); ref != "" {
+		addExtends(config, ref)
+	} else {
+		mergeMissing(config, builtinPatches())
+	}
+	if len(ignorePatterns) > 0 {
+		addFilesIgnore(config, ignorePatterns)
+	}
+
+	if opts.LocalSchema != "" {
+		config["$schema"] = localSchemaRef(dir, opts.LocalSchema)
+	} else if opts.BiomeVersion != "" {
+		config["$schema"] = schemaURL(opts.BiomeVersion)
+	}
+
+	for _, overlay := range opts.Overlays {
+		deepMerge(config, overlay)
+	}
+
+	if len(opts.jsonPatch) > 0 {
+		patched, err := applyJSONPatch(config, opts.jsonPatch)
+		if err != nil {
+			return nil, err
+		}
+		config = patched
+	}
+
+	return encodeConfig(config, opts)
+}
@@ -0,0 +1,181 @@
+package migrate
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPatchConfigKeepsNurseryRules(t *testing.T) {
+	nursery := map[string]any{
+		"noRestrictedTypes": "error",
+		"useSortedClasses":  map[string]any{"level": "warn", "options": map[string]any{"functions": []any{"clsx"}}},
+	}
+	withNursery := map[string]any{
+		"linter": map[string]any{
+			"enabled": true,
+			"rules":   map[string]any{"recommended": true, "nursery": nursery},
+		},
+		"organizeImports": map[string]any{"enabled": false},
+	}
+
+	tests := []struct {
+		name     string
+		migrated map[string]any
+		original map[string]any
+	}{
+		{
+			name:     "migrate kept the block",
+			migrated: withNursery,
+		},
+		{
+			name:     "migrate dropped the linter section",
+			migrated: map[string]any{"formatter": map[string]any{"indentStyle": "space"}},
+			original: withNursery,
+		},
+		{
+			name: "migrate dropped only the nursery group",
+			migrated: map[string]any{
+				"linter": map[string]any{"rules": map[string]any{"recommended": true, "style": map[string]any{"noVar": "error"}}},
+			},
+			original: withNursery,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.migrated)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var original map[string]any
+			if tt.original != nil {
+				original = cloneValue(tt.original).(map[string]any)
+			}
+
+			output, err := patchConfig(data, original, nil, t.TempDir(), t.TempDir(), &Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var config map[string]any
+			if err := json.Unmarshal(output, &config); err != nil {
+				t.Fatal(err)
+			}
+
+			linter, _ := config["linter"].(map[string]any)
+			rules, _ := linter["rules"].(map[string]any)
+			if got := rules["nursery"]; !reflect.DeepEqual(got, nursery) {
+				t.Errorf("linter.rules.nursery = %v, want %v", got, nursery)
+			}
+			formatter, _ := config["formatter"].(map[string]any)
+			if formatter["formatWithErrors"] != true {
+				t.Errorf("formatter.formatWithErrors = %v, want true", formatter["formatWithErrors"])
+			}
+		})
+	}
+}
+
+func TestMergeMissingKeepsExistingValues(t *testing.T) {
+	dst := map[string]any{
+		"formatter": map[string]any{"formatWithErrors": false},
+		"linter":    map[string]any{"rules": map[string]any{"nursery": map[string]any{"noFoo": "warn"}}},
+	}
+	mergeMissing(dst, builtinPatches())
+
+	want := map[string]any{
+		"formatter":  map[string]any{"formatWithErrors": false},
+		"linter":     map[string]any{"rules": map[string]any{"nursery": map[string]any{"noFoo": "warn"}}},
+		"javascript": map[string]any{"parser": map[string]any{"unsafeParameterDecoratorsEnabled": true}},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("mergeMissing = %v, want %v", dst, want)
+	}
+}
+
+func TestPatchConfigKeepsOverrides(t *testing.T) {
+	tsOff := map[string]any{"include": []any{"*.ts"}, "linter": map[string]any{"enabled": false}}
+	cssOff := map[string]any{"include": []any{"*.css"}, "formatter": map[string]any{"enabled": false}}
+	original := map[string]any{"overrides": []any{tsOff, cssOff}}
+
+	tests := []struct {
+		name     string
+		migrated map[string]any
+		want     []any
+	}{
+		{
+			name:     "migrate dropped the overrides",
+			migrated: map[string]any{"formatter": map[string]any{"indentStyle": "space"}},
+			want:     []any{tsOff, cssOff},
+		},
+		{
+			name:     "migrate kept only one entry",
+			migrated: map[string]any{"overrides": []any{cssOff}},
+			want:     []any{tsOff, cssOff},
+		},
+		{
+			name: "migrate added an entry",
+			migrated: map[string]any{"overrides": []any{
+				map[string]any{"include": []any{"*.md"}, "formatter": map[string]any{"lineWidth": float64(80)}},
+			}},
+			want: []any{
+				tsOff,
+				cssOff,
+				map[string]any{"include": []any{"*.md"}, "formatter": map[string]any{"lineWidth": float64(80)}},
+			},
+		},
+		{
+			name: "migrate changed an entry",
+			migrated: map[string]any{"overrides": []any{
+				map[string]any{"include": []any{"*.ts"}, "linter": map[string]any{"enabled": true}},
+			}},
+			want: []any{
+				map[string]any{"include": []any{"*.ts"}, "linter": map[string]any{"enabled": true}},
+				cssOff,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.migrated)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			output, err := patchConfig(data, cloneValue(original).(map[string]any), nil, t.TempDir(), t.TempDir(), &Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var config map[string]any
+			if err := json.Unmarshal(output, &config); err != nil {
+				t.Fatal(err)
+			}
+			if got := config["overrides"]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("overrides = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateArgs(t *testing.T) {
+	tests := []struct {
+		name, tool, dir, workDir string
+		want                     []string
+	}{
+		{"in place", "eslint", "/repo/packages/web", "/repo/packages/web", []string{"migrate", "eslint", "--write"}},
+		{"prettier in place", "prettier", "/repo", "/repo", []string{"migrate", "prettier", "--write"}},
+		{
+			"from package root", "eslint", "/repo/packages/web/src", "/repo/packages/web",
+			[]string{"migrate", "eslint", "--write", "--config-path", "/repo/packages/web/src"},
+		},
+		{
+			"prettier from package root", "prettier", "/repo/packages/web/src", "/repo",
+			[]string{"migrate", "prettier", "--write", "--config-path", "/repo/packages/web/src"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := migrateArgs(tt.tool, tt.dir, tt.workDir); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("migrateArgs(%q, %q, %q) = %q, want %q", tt.tool, tt.dir, tt.workDir, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package migrate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// rootConfigRef returns the reference from the config in dir to the Biome
+// config at the scan root, for -monorepo, or "" when dir is the root itself
+// or the root has no config and doesn't get one in this run.
+func rootConfigRef(dir, root string, opts *Options) string {
+	if !opts.Monorepo || root == "" || dir == root {
+		return ""
+	}
+
+	path := filepath.Join(root, configFileName(opts))
+	if !opts.monorepoRoots[root] {
+		path = existingConfigPath(root, opts)
+		if _, err := os.Stat(path); err != nil {
+			return ""
+		}
+	}
+
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// addExtends puts ref first in config's extends list, so the location's own
+// settings and any other extended configs override the root's.
+func addExtends(config map[string]any, ref string) {
+	var extends []any
+	switch v := config["extends"].(type) {
+	case []any:
+		extends = v
+	case string:
+		extends = []any{v}
+	}
+	if slices.Contains(extends, any(ref)) {
+		return
+	}
+	config["extends"] = append([]any{ref}, extends...)
+}
+
+// migrateMonorepo is migrateAll for -monorepo: the locations at the scan
+// roots go first, so the configs below them can extend root configs that
+// already exist.
+func migrateMonorepo(ctx context.Context, locations map[string]*ConfigLocation, dirs []string, opts *Options, out *printer, concurrency, maxFailures int) []*locationResult {
+	var rootDirs, rest []string
+	for _, dir := range dirs {
+		if loc := locations[dir]; loc.Dir == loc.Root {
+			rootDirs = append(rootDirs, dir)
+		} else {
+			rest = append(rest, dir)
+		}
+	}
+
+	results := migrateAll(ctx, locations, rootDirs, opts, out, concurrency, maxFailures)
+	failures := 0
+	for _, res := range results {
+		if res.failed() {
+			failures++
+		}
+	}
+	if len(results) < len(rootDirs) || (maxFailures > 0 && failures >= maxFailures) {
+		return results
+	}
+	if maxFailures > 0 {
+		maxFailures -= failures
+	}
+
+	// A dry run or one into -output-dir writes nothing next to the roots,
+	// so the children are told which roots got a config.
+	if opts.DryRun || opts.OutputDir != "" {
+		resolved := *opts
+		resolved.monorepoRoots = make(map[string]bool)
+		for _, res := range results {
+			if !res.failed() {
+				resolved.monorepoRoots[res.loc.Root] = true
+			}
+		}
+		opts = &resolved
+	}
+	return append(results, migrateAll(ctx, locations, rest, opts, out, concurrency, maxFailures)...)
+}
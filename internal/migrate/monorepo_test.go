@@ -0,0 +1,134 @@
+package migrate
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRootConfigRef(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  Options
+		dir   string
+		files map[string]string
+		roots bool
+		want  string
+	}{
+		{
+			name:  "monorepo off",
+			dir:   "packages/app",
+			files: map[string]string{"biome.json": "{}"},
+		},
+		{
+			name:  "root location",
+			opts:  Options{Monorepo: true},
+			files: map[string]string{"biome.json": "{}"},
+		},
+		{
+			name: "root has no config",
+			opts: Options{Monorepo: true},
+			dir:  "packages/app",
+		},
+		{
+			name:  "root config",
+			opts:  Options{Monorepo: true},
+			dir:   "packages/app",
+			files: map[string]string{"biome.json": "{}"},
+			want:  "../../biome.json",
+		},
+		{
+			name:  "jsonc extends the biome.json it renames",
+			opts:  Options{Monorepo: true, Emit: emitJSONC},
+			dir:   "packages/app",
+			files: map[string]string{"biome.json": "{}"},
+			want:  "../../biome.json",
+		},
+		{
+			// In a dry run the root gets its config in this run only.
+			name:  "root migrated in this run",
+			opts:  Options{Monorepo: true, Emit: emitJSONC},
+			dir:   "packages/app",
+			roots: true,
+			want:  "../../biome.jsonc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+			opts := tt.opts
+			if tt.roots {
+				opts.monorepoRoots = map[string]bool{root: true}
+			}
+
+			if got := rootConfigRef(filepath.Join(root, tt.dir), root, &opts); got != tt.want {
+				t.Errorf("rootConfigRef = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddExtends(t *testing.T) {
+	const ref = "../../biome.json"
+	tests := []struct {
+		name    string
+		extends any
+		want    []any
+	}{
+		{name: "no extends", want: []any{ref}},
+		{name: "string", extends: "./shared.json", want: []any{ref, "./shared.json"}},
+		{name: "list", extends: []any{"./a.json", "./b.json"}, want: []any{ref, "./a.json", "./b.json"}},
+		{name: "already extended", extends: []any{"./a.json", ref}, want: []any{"./a.json", ref}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]any{}
+			if tt.extends != nil {
+				config["extends"] = tt.extends
+			}
+			addExtends(config, ref)
+			if got := config["extends"]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extends = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatchConfigMonorepo(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"biome.json": "{}"})
+	opts := &Options{Monorepo: true}
+
+	tests := []struct {
+		name        string
+		dir         string
+		wantExtends any
+		wantPatches bool
+	}{
+		{name: "root", dir: root, wantPatches: true},
+		{name: "package", dir: filepath.Join(root, "packages", "app"), wantExtends: []any{"../../biome.json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := patchConfig([]byte(`{"linter": {"enabled": true}}`), nil, nil, tt.dir, root, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var config map[string]any
+			if err := json.Unmarshal(output, &config); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := config["extends"]; !reflect.DeepEqual(got, tt.wantExtends) {
+				t.Errorf("extends = %v, want %v", got, tt.wantExtends)
+			}
+			// The builtin patches are inherited from the root config.
+			_, patched := config["formatter"]
+			if patched != tt.wantPatches {
+				t.Errorf("builtin patches applied = %v, want %v:\n%s", patched, tt.wantPatches, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// outputPath returns where loc's config goes with -output-dir: the same
+// path relative to the input directory, under the output directory. A
+// location reached through a symlinked directory keeps its logical path.
+// With several input directories each one gets its own subdirectory, named
+// after it. A location outside its input directory has no such path.
+func outputPath(loc *ConfigLocation, opts *Options) (string, error) {
+	dir := loc.Dir
+	if loc.LogicalDir != "" {
+		dir = loc.LogicalDir
+	}
+	rel, err := filepath.Rel(loc.Root, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the input directory %s, so it has no place under -output-dir", dir, loc.Root)
+	}
+	out := filepath.Join(opts.OutputDir, rel)
+	if opts.outputPerRoot {
+		out = filepath.Join(opts.OutputDir, filepath.Base(loc.Root), rel)
+	}
+	return filepath.Join(out, configFileName(opts)), nil
+}
+
+// migrateToOutputDir migrates a temporary copy of loc, as the dry-run
+// preview does, and writes the resulting config to its -output-dir path.
+// The location itself is never written to.
+func migrateToOutputDir(ctx context.Context, loc *ConfigLocation, opts *Options, out *printer) *locationResult {
+	path, err := outputPath(loc, opts)
+	if err != nil {
+		out.errorf("Error: %v\n", err)
+		res := &locationResult{loc: loc}
+		res.addError(fmt.Errorf("output: %w", err))
+		return res
+	}
+
+	warnPreviewReferences(loc, out)
+	data, res, err := previewConfig(ctx, loc, opts, out)
+	if res == nil {
+		res = &locationResult{loc: loc}
+	}
+	if err != nil {
+		if !res.failed() {
+			out.errorf("Error migrating a copy of %s: %v\n", loc.Dir, err)
+			res.addError(err)
+		}
+		return res
+	}
+
+	_, statErr := os.Stat(path)
+	res.created = statErr != nil
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err == nil {
+		err = os.WriteFile(path, data, 0o644)
+	}
+	if err != nil {
+		out.errorf("Error writing %s: %v\n", path, err)
+		res.addError(fmt.Errorf("output: %w", err))
+		return res
+	}
+	res.outputPath = path
+	out.verbosef("Created: %s\n", path)
+
+	if opts.WriteBiomeignore {
+		patterns, err := biomeignorePatterns(loc.Dir, opts.BiomeignoreTemplate)
+		if err == nil {
+			err = writeBiomeignore(filepath.Dir(path), patterns)
+		}
+		if err != nil {
+			out.errorf("Error writing .biomeignore for %s: %v\n", loc.Dir, err)
+			res.addError(fmt.Errorf("biomeignore: %w", err))
+		} else {
+			out.infof("Created: %s\n", filepath.Join(filepath.Dir(path), ".biomeignore"))
+		}
+	}
+	return res
+}
+
+// printOutputDir lists, for the summary, where each config went with
+// -output-dir.
+func printOutputDir(out *printer, outputDir string, results []*locationResult) {
+	var written []*locationResult
+	for _, res := range results {
+		if res.outputPath != "" {
+			written = append(written, res)
+		}
+	}
+	out.infof("\nConfigs were written under %s; the input directories were not modified.\n", outputDir)
+	for _, res := range written {
+		out.infof("  - %s -> %s\n", res.loc.displayDir(), res.outputPath)
+	}
+}
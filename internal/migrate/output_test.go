@@ -0,0 +1,47 @@
+package migrate
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutputPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		loc     ConfigLocation
+		perRoot bool
+		want    string
+		wantErr bool
+	}{
+		{name: "root", loc: ConfigLocation{Root: "/repo", Dir: "/repo"}, want: "/out/biome.json"},
+		{name: "nested", loc: ConfigLocation{Root: "/repo", Dir: "/repo/packages/web"}, want: "/out/packages/web/biome.json"},
+		{name: "per root", loc: ConfigLocation{Root: "/repo", Dir: "/repo/web"}, perRoot: true, want: "/out/repo/web/biome.json"},
+		{
+			name: "alias keeps logical path",
+			loc:  ConfigLocation{Root: "/repo", Dir: "/shared/web", LogicalDir: "/repo/packages/web"},
+			want: "/out/packages/web/biome.json",
+		},
+		{name: "outside root", loc: ConfigLocation{Root: "/repo", Dir: "/shared/web"}, wantErr: true},
+		{name: "parent of root", loc: ConfigLocation{Root: "/repo/sub", Dir: "/repo"}, wantErr: true},
+		{name: "dot-dot prefixed name", loc: ConfigLocation{Root: "/repo", Dir: "/repo/..web"}, want: "/out/..web/biome.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &Options{OutputDir: "/out", Emit: emitJSON, outputPerRoot: tt.perRoot}
+			got, err := outputPath(&tt.loc, opts)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "outside the input directory") {
+					t.Fatalf("outputPath = %q, %v, want an outside error", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != filepath.FromSlash(tt.want) {
+				t.Errorf("outputPath = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
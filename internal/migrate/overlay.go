@@ -0,0 +1,161 @@
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// loadOverlays reads each overlay file in order. Every file must hold a JSON
+// object; the error names the file that failed to parse.
+func loadOverlays(paths []string) ([]map[string]any, error) {
+	overlays := make([]map[string]any, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("overlay %s: %w", path, err)
+		}
+
+		var overlay map[string]any
+		if err := json.Unmarshal(data, &overlay); err != nil {
+			return nil, fmt.Errorf("overlay %s: %w", path, err)
+		}
+		if overlay == nil {
+			return nil, fmt.Errorf("overlay %s: expected a JSON object", path)
+		}
+		overlays = append(overlays, overlay)
+	}
+	return overlays, nil
+}
+
+// deepMerge merges src into dst. Nested objects are merged key by key and
+// "overrides" arrays entry by entry (see mergeOverrides); a null in src
+// removes the key from dst, and any other value replaces the one in dst.
+func deepMerge(dst, src map[string]any) {
+	for key, srcVal := range src {
+		if srcVal == nil {
+			delete(dst, key)
+			continue
+		}
+		if key == "overrides" {
+			srcList, srcIsList := srcVal.([]any)
+			dstList, dstIsList := dst[key].([]any)
+			if srcIsList && dstIsList {
+				dst[key] = mergeOverrides(dstList, srcList)
+				continue
+			}
+		}
+
+		srcMap, srcIsMap := srcVal.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		if srcIsMap && dstIsMap {
+			deepMerge(dstMap, srcMap)
+			continue
+		}
+		dst[key] = cloneValue(srcVal)
+	}
+}
+
+// mergeMissing copies into dst the keys of src that dst lacks, recursing
+// into objects present in both. Values already in dst are never changed.
+// Overrides entries are merged with mergeOverrides, src's first, so the
+// entries dst lacks are kept and dst's entries win over src's ones with the
+// same include globs.
+func mergeMissing(dst, src map[string]any) {
+	for key, srcVal := range src {
+		dstVal, ok := dst[key]
+		if !ok {
+			dst[key] = cloneValue(srcVal)
+			continue
+		}
+		if key == "overrides" {
+			srcList, srcIsList := srcVal.([]any)
+			dstList, dstIsList := dstVal.([]any)
+			if srcIsList && dstIsList {
+				dst[key] = mergeOverrides(cloneValue(srcList).([]any), dstList)
+				continue
+			}
+		}
+		srcMap, srcIsMap := srcVal.(map[string]any)
+		dstMap, dstIsMap := dstVal.(map[string]any)
+		if srcIsMap && dstIsMap {
+			mergeMissing(dstMap, srcMap)
+		}
+	}
+}
+
+// mergeOverrides merges Biome "overrides" entries. An entry in src whose
+// include globs equal those of an entry in dst is deep-merged into it in
+// place; any other entry is appended. Existing entries are never dropped or
+// duplicated.
+func mergeOverrides(dst, src []any) []any {
+	for _, srcVal := range src {
+		srcEntry, ok := srcVal.(map[string]any)
+		if !ok {
+			dst = append(dst, cloneValue(srcVal))
+			continue
+		}
+
+		merged := false
+		for _, dstVal := range dst {
+			dstEntry, ok := dstVal.(map[string]any)
+			if ok && sameIncludes(dstEntry, srcEntry) {
+				deepMerge(dstEntry, srcEntry)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			dst = append(dst, cloneValue(srcEntry))
+		}
+	}
+	return dst
+}
+
+// sameIncludes reports whether two override entries target the same globs.
+// Biome 1.x calls the key "include" and 2.x "includes"; the order of the
+// globs doesn't matter.
+func sameIncludes(a, b map[string]any) bool {
+	globs := func(entry map[string]any) []string {
+		raw, ok := entry["include"].([]any)
+		if !ok {
+			raw, ok = entry["includes"].([]any)
+		}
+		if !ok {
+			return nil
+		}
+		var list []string
+		for _, g := range raw {
+			if s, ok := g.(string); ok {
+				list = append(list, s)
+			}
+		}
+		slices.Sort(list)
+		return list
+	}
+
+	ga, gb := globs(a), globs(b)
+	return ga != nil && slices.Equal(ga, gb)
+}
+
+// cloneValue deep-copies a decoded JSON value so overlays shared between
+// locations are never aliased into a single config.
+func cloneValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, val := range v {
+			m[key] = cloneValue(val)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, val := range v {
+			s[i] = cloneValue(val)
+		}
+		return s
+	default:
+		return v
+	}
+}
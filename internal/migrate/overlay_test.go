@@ -0,0 +1,119 @@
+package migrate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeOverrides(t *testing.T) {
+	entry := func(include []any, settings map[string]any) map[string]any {
+		e := map[string]any{"include": include}
+		for key, value := range settings {
+			e[key] = value
+		}
+		return e
+	}
+
+	tests := []struct {
+		name     string
+		dst, src []any
+		want     []any
+	}{
+		{
+			name: "same include merged in place",
+			dst: []any{
+				entry([]any{"*.test.ts"}, map[string]any{"linter": map[string]any{"enabled": false}}),
+			},
+			src: []any{
+				entry([]any{"*.test.ts"}, map[string]any{"formatter": map[string]any{"lineWidth": 120.0}}),
+			},
+			want: []any{
+				entry([]any{"*.test.ts"}, map[string]any{
+					"linter":    map[string]any{"enabled": false},
+					"formatter": map[string]any{"lineWidth": 120.0},
+				}),
+			},
+		},
+		{
+			// The overlay's include replaces the equal one, like any array.
+			name: "same globs in another order merged",
+			dst:  []any{entry([]any{"a/**", "b/**"}, map[string]any{"linter": map[string]any{"enabled": false}})},
+			src:  []any{entry([]any{"b/**", "a/**"}, map[string]any{"linter": map[string]any{"enabled": true}})},
+			want: []any{entry([]any{"b/**", "a/**"}, map[string]any{"linter": map[string]any{"enabled": true}})},
+		},
+		{
+			name: "includes key of Biome 2 matched",
+			dst:  []any{map[string]any{"includes": []any{"*.js"}, "linter": map[string]any{"enabled": false}}},
+			src:  []any{map[string]any{"includes": []any{"*.js"}, "linter": map[string]any{"enabled": true}}},
+			want: []any{map[string]any{"includes": []any{"*.js"}, "linter": map[string]any{"enabled": true}}},
+		},
+		{
+			name: "disjoint include appended",
+			dst:  []any{entry([]any{"*.test.ts"}, nil)},
+			src:  []any{entry([]any{"scripts/**"}, nil)},
+			want: []any{entry([]any{"*.test.ts"}, nil), entry([]any{"scripts/**"}, nil)},
+		},
+		{
+			name: "order kept with a mix",
+			dst: []any{
+				entry([]any{"a"}, map[string]any{"x": 1.0}),
+				entry([]any{"b"}, map[string]any{"x": 2.0}),
+			},
+			src: []any{
+				entry([]any{"c"}, map[string]any{"x": 3.0}),
+				entry([]any{"a"}, map[string]any{"y": 1.0}),
+				entry([]any{"d"}, map[string]any{"x": 4.0}),
+			},
+			want: []any{
+				entry([]any{"a"}, map[string]any{"x": 1.0, "y": 1.0}),
+				entry([]any{"b"}, map[string]any{"x": 2.0}),
+				entry([]any{"c"}, map[string]any{"x": 3.0}),
+				entry([]any{"d"}, map[string]any{"x": 4.0}),
+			},
+		},
+		{
+			name: "entries without include never match",
+			dst:  []any{map[string]any{"linter": map[string]any{"enabled": false}}},
+			src:  []any{map[string]any{"linter": map[string]any{"enabled": true}}},
+			want: []any{
+				map[string]any{"linter": map[string]any{"enabled": false}},
+				map[string]any{"linter": map[string]any{"enabled": true}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeOverrides(tt.dst, tt.src); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeOverrides =\n  %v\nwant\n  %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeepMergeOverrides(t *testing.T) {
+	dst := map[string]any{
+		"overrides": []any{map[string]any{"include": []any{"*.ts"}, "linter": map[string]any{"enabled": false}}},
+	}
+	overlay := map[string]any{
+		"overrides": []any{map[string]any{"include": []any{"*.css"}, "formatter": map[string]any{"enabled": false}}},
+		"vcs":       map[string]any{"enabled": true},
+	}
+	deepMerge(dst, overlay)
+
+	want := map[string]any{
+		"overrides": []any{
+			map[string]any{"include": []any{"*.ts"}, "linter": map[string]any{"enabled": false}},
+			map[string]any{"include": []any{"*.css"}, "formatter": map[string]any{"enabled": false}},
+		},
+		"vcs": map[string]any{"enabled": true},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("deepMerge = %v, want %v", dst, want)
+	}
+
+	// The overlay must not be aliased into the config.
+	overlay["overrides"].([]any)[0].(map[string]any)["formatter"].(map[string]any)["enabled"] = true
+	if dst["overrides"].([]any)[1].(map[string]any)["formatter"].(map[string]any)["enabled"] != false {
+		t.Error("deepMerge aliased the overlay's overrides entry into the config")
+	}
+}
@@ -0,0 +1,144 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// migrateAll migrates the locations in dirs with up to concurrency workers.
+// With more than one worker, each location's output is buffered and written
+// in one piece once it finishes, so logs from different directories don't
+// interleave. Once maxFailures locations have failed (0 means no limit), no
+// new locations are started. The results are in dirs order and cover only
+// the locations that were processed.
+func migrateAll(ctx context.Context, locations map[string]*ConfigLocation, dirs []string, opts *Options, out *printer, concurrency, maxFailures int) []*locationResult {
+	results := make([]*locationResult, len(dirs))
+	jobs := make(chan int)
+
+	var (
+		mu       sync.Mutex
+		started  int
+		failures int
+		stop     bool
+		wg       sync.WaitGroup
+	)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				mu.Lock()
+				if stop || ctx.Err() != nil {
+					mu.Unlock()
+					continue
+				}
+				started++
+				out.progress.step(started, len(dirs), dirs[i])
+				mu.Unlock()
+
+				locOut := out
+				var buf bytes.Buffer
+				if concurrency > 1 {
+					locOut = out.bufferedTo(&buf)
+				}
+				res := migrateOrSkip(ctx, locations[dirs[i]], opts, locOut)
+				if !opts.DryRun {
+					printOutcome(locOut, res)
+				}
+
+				mu.Lock()
+				if concurrency > 1 {
+					out.printf("%s", buf.String())
+				}
+				results[i] = res
+				if res.failed() {
+					failures++
+				}
+				if maxFailures > 0 && failures >= maxFailures {
+					stop = true
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range dirs {
+		mu.Lock()
+		stopped := stop
+		mu.Unlock()
+		if stopped || ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	out.progress.done()
+
+	var processed []*locationResult
+	for _, res := range results {
+		if res != nil {
+			processed = append(processed, res)
+		}
+	}
+	return processed
+}
+
+// migrateOrSkip migrates loc unless -interactive is set and the user
+// declines. A dry run shows its preview before asking.
+func migrateOrSkip(ctx context.Context, loc *ConfigLocation, opts *Options, out *printer) *locationResult {
+	if opts.DryRun {
+		res := migrateLocation(ctx, loc, opts, out)
+		if !res.skipped {
+			res.skipped = !opts.confirm.confirm(out, loc)
+		}
+		return res
+	}
+	if !opts.confirm.confirm(out, loc) {
+		return &locationResult{loc: loc, skipped: true}
+	}
+	return migrateLocation(ctx, loc, opts, out)
+}
+
+// printOutcome prints the one-line result of a location. With -v each step
+// was already shown, so only structured logs get the outcome record.
+func printOutcome(out *printer, res *locationResult) {
+	dir := res.loc.displayDir()
+	switch {
+	case res.failed():
+		if out.logAttrs(slog.LevelError, "migration failed", "dir", dir, "error", res.errorMessage()) {
+			return
+		}
+	case res.skipped:
+		if out.logAttrs(slog.LevelInfo, "skipped", "dir", dir, "tracked", res.tracked) {
+			return
+		}
+	default:
+		if out.logAttrs(slog.LevelInfo, "migrated", "dir", dir, "tools", res.loc.tools(), "disabled", res.loc.disabledTools()) {
+			return
+		}
+	}
+	if out.verbose {
+		return
+	}
+	if res.failed() {
+		out.infof("✗ %s: %s\n", res.loc.displayDir(), res.errorMessage())
+		return
+	}
+	if res.skipped {
+		if res.tracked {
+			out.infof("- %s: skipped, its config is tracked by git\n", res.loc.displayDir())
+			return
+		}
+		out.infof("- %s: skipped\n", res.loc.displayDir())
+		return
+	}
+	if notes := res.loc.disabledNotes(); notes != "" {
+		out.infof("✓ %s [%s] (%s)\n", res.loc.displayDir(), strings.Join(res.loc.tools(), ", "), notes)
+		return
+	}
+	out.infof("✓ %s [%s]\n", res.loc.displayDir(), strings.Join(res.loc.tools(), ", "))
+}
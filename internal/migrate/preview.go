@@ -0,0 +1,161 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// previewConfig migrates a copy of loc in a temporary directory and returns
+// the resulting biome.json, leaving the source directory untouched. Only
+// the regular files directly in loc.Dir are copied; that covers the configs,
+// ignore files and package.json migrate reads, but not what they extend or
+// load as plugins (see warnPreviewReferences).
+func previewConfig(ctx context.Context, loc *ConfigLocation, opts *Options, out *printer) ([]byte, *locationResult, error) {
+	tmp, err := os.MkdirTemp("", "biome-preview-")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := copyDirFiles(loc.Dir, tmp); err != nil {
+		return nil, nil, err
+	}
+
+	preview := *loc
+	preview.Dir = tmp
+	preview.previewOf = loc.Dir
+	previewOpts := *opts
+	previewOpts.DryRun = false
+	previewOpts.FromPackageRoot = false
+	previewOpts.Backup = false
+	previewOpts.WriteBiomeignore = false
+	previewOpts.Cleanup = false
+	previewOpts.OutputDir = ""
+
+	res := migrateLocation(ctx, &preview, &previewOpts, out)
+	res.loc = loc
+	if res.failed() {
+		return nil, res, res.errs[0]
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmp, configFileName(opts)))
+	return data, res, err
+}
+
+// previewReferences are the config keys that make migrate load other files,
+// such as shared configs and plugins resolved from node_modules.
+var previewReferences = [][]byte{[]byte("extends"), []byte("plugins")}
+
+// warnPreviewReferences warns when a config at loc extends or loads files
+// that the preview's copy of loc may not reach, so migrating it could fail
+// or give another result than a real run.
+func warnPreviewReferences(loc *ConfigLocation, out *printer) {
+	for _, path := range loc.Files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, key := range previewReferences {
+			if bytes.Contains(data, key) {
+				out.warnf("Warning: %s uses %q; the preview only copies the files directly in %s, so what it references may not resolve and the result can differ from an in-place run\n", path, key, loc.Dir)
+				break
+			}
+		}
+	}
+}
+
+// printConfigDiff prints, for dry-run, a unified diff from loc's current
+// biome.json to the one a real run would write, computed by migrating a
+// temporary copy. Without a current file the whole proposed config is
+// shown. It reports whether the file would be created or changed; failures
+// are recorded on res.
+func printConfigDiff(ctx context.Context, loc *ConfigLocation, opts *Options, out *printer, res *locationResult) bool {
+	warnPreviewReferences(loc, out)
+	var log bytes.Buffer
+	proposed, previewRes, err := previewConfig(ctx, loc, opts, out.bufferedTo(&log))
+	if previewRes != nil {
+		res.lockfileConflict = previewRes.lockfileConflict
+		res.succeeded = previewRes.succeeded
+		res.failedTools = previewRes.failedTools
+		res.disabledTools = previewRes.disabledTools
+	}
+	if err != nil {
+		out.errorf("[DRY RUN]   - Could not compute the resulting biome.json: %v\n", err)
+		out.printf("%s", log.String())
+		res.addError(fmt.Errorf("preview: %w", err))
+		return true
+	}
+	proposed = append(proposed, '\n')
+
+	path := filepath.Join(loc.Dir, configFileName(opts))
+	oldName := existingConfigPath(loc.Dir, opts)
+	if opts.OutputDir != "" {
+		if path, err = outputPath(loc, opts); err != nil {
+			out.errorf("[DRY RUN]   - Error: %v\n", err)
+			res.addError(fmt.Errorf("output: %w", err))
+			return true
+		}
+		oldName = path
+	}
+	current, err := os.ReadFile(oldName)
+	if err != nil {
+		oldName = "/dev/null"
+	}
+	diff := unifiedDiff(oldName, path, current, proposed)
+	name := configFileName(opts)
+	if diff == "" {
+		out.infof("[DRY RUN]   - %s would not change\n", name)
+		return false
+	}
+
+	if current == nil {
+		out.infof("[DRY RUN]   - %s would be created:\n", name)
+	} else {
+		out.infof("[DRY RUN]   - %s would change:\n", name)
+	}
+	out.infof("%s", diff)
+	return true
+}
+
+// copyDirFiles copies the regular files directly inside src into dst.
+// Symlinked files are copied by content.
+func copyDirFiles(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(src, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if err := copyFile(path, filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,122 @@
+package migrate
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// progress draws a spinner or an i/N progress bar on the last line of stderr.
+// A nil *progress draws nothing, so callers don't need to check whether
+// progress output is enabled.
+type progress struct {
+	mu   sync.Mutex
+	line string
+}
+
+// newProgress returns a progress indicator when stderr is an interactive
+// terminal, or nil otherwise.
+func newProgress() *progress {
+	if !isTerminal(os.Stderr) {
+		return nil
+	}
+	return &progress{}
+}
+
+// isTerminal reports whether f is an interactive terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// spin shows a spinner next to label until the returned function is called.
+func (p *progress) spin(label string) (stop func()) {
+	if p == nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		frames := `|/-\`
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for i := 0; ; i++ {
+			p.set(fmt.Sprintf("%c %s", frames[i%len(frames)], label))
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+		p.set("")
+	}
+}
+
+// step shows a progress bar for item i of n, counting from 1.
+func (p *progress) step(i, n int, label string) {
+	if p == nil {
+		return
+	}
+	const width = 20
+	filled := width * i / n
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", width-filled)
+	p.set(fmt.Sprintf("[%s] %d/%d %s", bar, i, n, label))
+}
+
+// done removes the progress line.
+func (p *progress) done() {
+	if p == nil {
+		return
+	}
+	p.set("")
+}
+
+func (p *progress) set(line string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.line = line
+	fmt.Fprint(os.Stderr, "\r\033[K"+line)
+}
+
+// around clears the progress line, runs write and draws the line again, so
+// regular output never lands on top of it. The line is only redrawn once the
+// output has finished a line of its own.
+func (p *progress) around(write func() []byte) {
+	if p == nil {
+		write()
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.line != "" {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+	data := write()
+	if p.line != "" && (len(data) == 0 || data[len(data)-1] == '\n') {
+		fmt.Fprint(os.Stderr, p.line)
+	}
+}
+
+// progressWriter routes child process output through progress.around.
+type progressWriter struct {
+	p *progress
+	w io.Writer
+}
+
+func (pw progressWriter) Write(b []byte) (n int, err error) {
+	pw.p.around(func() []byte {
+		n, err = pw.w.Write(b)
+		return b[:n]
+	})
+	return n, err
+}
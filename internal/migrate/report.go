@@ -0,0 +1,225 @@
+package migrate
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+var reportFormats = []string{"csv", "json"}
+
+// newRunID returns an identifier for the current invocation: a UTC
+// timestamp followed by a short random suffix.
+func newRunID() string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return time.Now().UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+}
+
+// locationResult records what happened to a single location during the run.
+type locationResult struct {
+	loc      *ConfigLocation
+	migrated bool
+	// created is set when the location had no biome.json before the run,
+	// as opposed to one that was migrated into and patched.
+	created  bool
+	noConfig bool
+	// reparseFailed is set when the written biome.json didn't parse when
+	// read back.
+	reparseFailed bool
+	// succeeded and failedTools list the tools whose migration went
+	// through or failed; in dry-run, what a real run would do.
+	succeeded   []string
+	failedTools []string
+	// disabledTools lists the tools skipped because their configs are
+	// empty or switched off.
+	disabledTools []string
+	// changes lists the files the migration created, patched or deleted
+	// when Options.recordChanges is set.
+	changes []fileChange
+	// restore is set when the location failed after -backup copied its
+	// files, for offerRestore to undo it once the run has finished.
+	restore *pendingRestore
+	// outputPath is where the config was written with -output-dir.
+	outputPath string
+	// skipped is set when the location was declined at the -interactive
+	// prompt, or, with tracked, because its biome.json is committed.
+	skipped bool
+	tracked bool
+	// timedOut is set when a biome command ran past -timeout.
+	timedOut bool
+	// outOfDate is set in dry-run when a real run would create or change
+	// the location's biome.json.
+	outOfDate bool
+	// schemaViolations lists where the proposed biome.json doesn't match
+	// the Biome schema, with -dry-run -validate.
+	schemaViolations []schemaViolation
+	// lockfileConflict lists the lockfiles of different package managers
+	// that made runner auto-detection ambiguous.
+	lockfileConflict []string
+	// eslintComparison holds the -compare-eslint outcome.
+	eslintComparison *eslintComparison
+	// diagnostics holds the biome check counts when -run-check is set.
+	diagnostics *diagnosticCounts
+	errs        []error
+	// migrateOutput holds biome migrate's captured output per tool when
+	// -migrate-json-reporter is set.
+	migrateOutput map[string]*migrateOutput
+}
+
+func (r *locationResult) setMigrateOutput(tool string, output *migrateOutput) {
+	if output == nil {
+		return
+	}
+	if r.migrateOutput == nil {
+		r.migrateOutput = make(map[string]*migrateOutput)
+	}
+	r.migrateOutput[tool] = output
+}
+
+func (r *locationResult) addError(err error) {
+	r.errs = append(r.errs, err)
+	if errors.Is(err, errTimedOut) {
+		r.timedOut = true
+	}
+}
+
+func (r *locationResult) failed() bool {
+	return len(r.errs) > 0
+}
+
+func (r *locationResult) errorMessage() string {
+	if !r.failed() {
+		return ""
+	}
+	return errors.Join(r.errs...).Error()
+}
+
+// writeReport writes the per-location results to path, or to stdout when
+// path is "-".
+func writeReport(path, format, runID string, results []*locationResult) error {
+	w := io.Writer(os.Stdout)
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch format {
+	case "csv":
+		return writeCSVReport(w, runID, results)
+	case "json":
+		return writeJSONReport(w, runID, results)
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+func writeCSVReport(w io.Writer, runID string, results []*locationResult) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"directory", "hasEslint", "hasPrettier", "migrated", "error", "runId", "logicalDirectory", "hasStylelint"}); err != nil {
+		return err
+	}
+
+	for _, res := range results {
+		record := []string{
+			res.loc.Dir,
+			strconv.FormatBool(res.loc.HasEslint),
+			strconv.FormatBool(res.loc.HasPrettier),
+			strconv.FormatBool(res.migrated && !res.failed()),
+			res.errorMessage(),
+			runID,
+			res.loc.LogicalDir,
+			strconv.FormatBool(res.loc.HasStylelint),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// jsonReport is the document written by -report-format json and -format json.
+type jsonReport struct {
+	RunID     string               `json:"runId"`
+	Migrated  int                  `json:"migrated"`
+	Failed    int                  `json:"failed"`
+	Skipped   int                  `json:"skipped"`
+	Locations []jsonReportLocation `json:"locations"`
+}
+
+type jsonReportLocation struct {
+	Directory        string `json:"directory"`
+	LogicalDirectory string `json:"logicalDirectory,omitempty"`
+	HasEslint        bool   `json:"hasEslint"`
+	HasPrettier      bool   `json:"hasPrettier"`
+	HasStylelint     bool   `json:"hasStylelint"`
+	Migrated         bool   `json:"migrated"`
+	// BiomeConfig is "created" or "patched" for migrated locations.
+	BiomeConfig      string                    `json:"biomeConfig,omitempty"`
+	Error            string                    `json:"error,omitempty"`
+	OutOfDate        bool                      `json:"outOfDate,omitempty"`
+	TimedOut         bool                      `json:"timedOut,omitempty"`
+	Skipped          bool                      `json:"skipped,omitempty"`
+	LockfileConflict []string                  `json:"lockfileConflict,omitempty"`
+	Diagnostics      *jsonReportDiagnostics    `json:"diagnostics,omitempty"`
+	MigrateOutput    map[string]*migrateOutput `json:"migrateOutput,omitempty"`
+}
+
+type jsonReportDiagnostics struct {
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+}
+
+func writeJSONReport(w io.Writer, runID string, results []*locationResult) error {
+	report := jsonReport{RunID: runID, Locations: []jsonReportLocation{}}
+	for _, res := range results {
+		loc := jsonReportLocation{
+			Directory:        res.loc.Dir,
+			LogicalDirectory: res.loc.LogicalDir,
+			HasEslint:        res.loc.HasEslint,
+			HasPrettier:      res.loc.HasPrettier,
+			HasStylelint:     res.loc.HasStylelint,
+			Migrated:         res.migrated && !res.failed(),
+			Error:            res.errorMessage(),
+			OutOfDate:        res.outOfDate,
+			TimedOut:         res.timedOut,
+			Skipped:          res.skipped,
+			LockfileConflict: res.lockfileConflict,
+			MigrateOutput:    res.migrateOutput,
+		}
+		if res.migrated {
+			loc.BiomeConfig = "patched"
+			if res.created {
+				loc.BiomeConfig = "created"
+			}
+		}
+		if res.diagnostics != nil {
+			loc.Diagnostics = &jsonReportDiagnostics{Errors: res.diagnostics.errors, Warnings: res.diagnostics.warnings}
+		}
+		if res.failed() {
+			report.Failed++
+		} else if res.skipped {
+			report.Skipped++
+		} else if res.migrated {
+			report.Migrated++
+		}
+		report.Locations = append(report.Locations, loc)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
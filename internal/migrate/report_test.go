@@ -0,0 +1,40 @@
+package migrate
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// Paths and errors with commas, quotes and newlines come back intact from a
+// CSV reader.
+func TestWriteCSVReport(t *testing.T) {
+	results := []*locationResult{
+		{loc: &ConfigLocation{Dir: "/repo/a,b", HasEslint: true}, migrated: true},
+		{loc: &ConfigLocation{Dir: `/repo/say "hi"`, HasPrettier: true, LogicalDir: `/logical/"quoted", too`}, migrated: true},
+		{loc: &ConfigLocation{Dir: "/repo/line\nbreak", HasStylelint: true}, migrated: true, errs: []error{errors.New("eslint migration: exit 1,\n\"bad\" config")}},
+		{loc: &ConfigLocation{Dir: "/repo/plain"}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeCSVReport(&buf, "run,1", results); err != nil {
+		t.Fatal(err)
+	}
+	got, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"directory", "hasEslint", "hasPrettier", "migrated", "error", "runId", "logicalDirectory", "hasStylelint"},
+		{"/repo/a,b", "true", "false", "true", "", "run,1", "", "false"},
+		{`/repo/say "hi"`, "false", "true", "true", "", "run,1", `/logical/"quoted", too`, "false"},
+		{"/repo/line\nbreak", "false", "false", "false", "eslint migration: exit 1,\n\"bad\" config", "run,1", "", "true"},
+		{"/repo/plain", "false", "false", "false", "", "run,1", "", "false"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("read back %q, want %q", got, want)
+	}
+}
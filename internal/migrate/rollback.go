@@ -0,0 +1,281 @@
+package migrate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// RollbackManifestName is the file at each input directory that records a
+// run's changes for -rollback.
+const RollbackManifestName = ".biome-migration-manifest.json"
+
+// Kinds of fileChange.
+const (
+	changeCreated = "created"
+	changePatched = "patched"
+	changeDeleted = "deleted"
+)
+
+// undoVerbs describe undoing each kind of change, done and planned.
+var undoVerbs = map[string][2]string{
+	changeCreated: {"Removed", "Would remove"},
+	changePatched: {"Restored", "Would restore"},
+	changeDeleted: {"Recreated", "Would recreate"},
+}
+
+// fileChange is one file a run created, patched or deleted. Original holds
+// the file's prior content, or Link its symlink target, so it can be put
+// back; SHA256 is the hash of what the run left, for spotting later edits.
+type fileChange struct {
+	Action   string      `json:"action"`
+	Path     string      `json:"path"`
+	SHA256   string      `json:"sha256,omitempty"`
+	Original []byte      `json:"original,omitempty"`
+	Link     string      `json:"link,omitempty"`
+	Mode     fs.FileMode `json:"mode,omitempty"`
+}
+
+// rollbackManifest is the content of a RollbackManifestName file.
+type rollbackManifest struct {
+	RunID     string       `json:"runId"`
+	CreatedAt time.Time    `json:"createdAt"`
+	Changes   []fileChange `json:"changes"`
+}
+
+// fileState is a file as it was at one point of the run; a missing file has
+// exists unset.
+type fileState struct {
+	exists bool
+	data   []byte
+	link   string
+	mode   fs.FileMode
+}
+
+// watchedFiles returns every file a real run may create, rewrite or delete
+// at loc: the Biome config in both formats, .biomeignore, the backups of
+// this run and, for -cleanup, the detected config files.
+func watchedFiles(loc *ConfigLocation, opts *Options) []string {
+	var paths []string
+	for _, name := range migrateTouchedFiles {
+		path := filepath.Join(loc.Dir, name)
+		paths = append(paths, path, path+"."+opts.backupStamp+".bak")
+	}
+	paths = append(paths, filepath.Join(loc.Dir, ".biomeignore"))
+	for _, path := range loc.Files {
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// snapshotFiles records the current state of each path.
+func snapshotFiles(paths []string) map[string]fileState {
+	states := make(map[string]fileState, len(paths))
+	for _, path := range paths {
+		states[path] = readFileState(path)
+	}
+	return states
+}
+
+func readFileState(path string) fileState {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return fileState{}
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		link, err := os.Readlink(path)
+		return fileState{exists: err == nil, link: link}
+	}
+	data, err := os.ReadFile(path)
+	return fileState{exists: err == nil, data: data, mode: info.Mode().Perm()}
+}
+
+// changesSince compares the files in before with their current state and
+// returns what changed, in path order.
+func changesSince(before map[string]fileState) []fileChange {
+	paths := make([]string, 0, len(before))
+	for path := range before {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	var changes []fileChange
+	for _, path := range paths {
+		old, now := before[path], readFileState(path)
+		switch {
+		case !old.exists && now.exists:
+			changes = append(changes, fileChange{Action: changeCreated, Path: path, SHA256: now.hash()})
+		case old.exists && !now.exists:
+			changes = append(changes, fileChange{Action: changeDeleted, Path: path, Original: old.data, Link: old.link, Mode: old.mode})
+		case old.exists && (old.link != now.link || !bytes.Equal(old.data, now.data)):
+			changes = append(changes, fileChange{Action: changePatched, Path: path, SHA256: now.hash(), Original: old.data, Link: old.link, Mode: old.mode})
+		}
+	}
+	return changes
+}
+
+// hash identifies the file's content, or its target for a symlink.
+func (s fileState) hash() string {
+	data := s.data
+	if s.link != "" {
+		data = []byte("symlink:" + s.link)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// writeRollbackManifests writes, at each input directory, the changes the
+// run made to the locations found under it. Directories where nothing
+// changed get no manifest, and keep the one from an earlier run.
+func writeRollbackManifests(runID string, results []*locationResult) error {
+	byRoot := make(map[string][]fileChange)
+	var roots []string
+	for _, res := range results {
+		if len(res.changes) == 0 {
+			continue
+		}
+		root := res.loc.Root
+		if _, ok := byRoot[root]; !ok {
+			roots = append(roots, root)
+		}
+		byRoot[root] = append(byRoot[root], res.changes...)
+	}
+
+	var errs []error
+	for _, root := range roots {
+		manifest := rollbackManifest{RunID: runID, CreatedAt: time.Now().UTC(), Changes: byRoot[root]}
+		data, err := json.MarshalIndent(manifest, "", "  ")
+		if err == nil {
+			err = os.WriteFile(filepath.Join(root, RollbackManifestName), append(data, '\n'), 0o644)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", root, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// readRollbackManifest reads the manifest at root.
+func readRollbackManifest(root string) (*rollbackManifest, error) {
+	data, err := os.ReadFile(filepath.Join(root, RollbackManifestName))
+	if err != nil {
+		return nil, err
+	}
+	var manifest rollbackManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Join(root, RollbackManifestName), err)
+	}
+	return &manifest, nil
+}
+
+// changedSince reports why the file of c no longer is what the run left,
+// or "" when it still is.
+func (c fileChange) changedSince() string {
+	now := readFileState(c.Path)
+	switch {
+	case c.Action == changeDeleted && now.exists:
+		return "was recreated since the run"
+	case c.Action != changeDeleted && !now.exists:
+		return "was deleted since the run"
+	case c.Action != changeDeleted && now.hash() != c.SHA256:
+		return "was modified since the run"
+	}
+	return ""
+}
+
+// undo reverses c: a created file is removed, a patched or deleted one gets
+// its original content or symlink back.
+func (c fileChange) undo() error {
+	if c.Action == changeCreated {
+		err := os.Remove(c.Path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	if err := os.Remove(c.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if c.Link != "" {
+		return os.Symlink(c.Link, c.Path)
+	}
+	mode := c.Mode
+	if mode == 0 {
+		mode = 0o644
+	}
+	return os.WriteFile(c.Path, c.Original, mode)
+}
+
+// rollback undoes the run recorded at each root, newest change first. It
+// checks every root before touching anything and refuses when files have
+// changed since the run, unless force is set. With dryRun it only reports
+// what it would do. The manifest is removed once its run was undone.
+func rollback(roots []string, force, dryRun bool, out *printer) error {
+	manifests := make(map[string]*rollbackManifest)
+	var conflicts []string
+	for _, root := range roots {
+		manifest, err := readRollbackManifest(root)
+		if errors.Is(err, os.ErrNotExist) {
+			out.warnf("Warning: no %s in %s, nothing to roll back there\n", RollbackManifestName, root)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		manifests[root] = manifest
+		for _, c := range manifest.Changes {
+			if why := c.changedSince(); why != "" {
+				conflicts = append(conflicts, c.Path+" "+why)
+			}
+		}
+	}
+
+	if len(conflicts) > 0 && !force {
+		out.errorf("Refusing to roll back, %d file(s) changed after the run (use -force to roll back anyway):\n", len(conflicts))
+		for _, conflict := range conflicts {
+			out.errorf("  - %s\n", conflict)
+		}
+		return fmt.Errorf("%d file(s) changed since the run", len(conflicts))
+	}
+
+	var errs []error
+	for _, root := range roots {
+		manifest := manifests[root]
+		if manifest == nil {
+			continue
+		}
+		out.infof("Rolling back run %s in %s\n", manifest.RunID, root)
+		failed := false
+		for i := len(manifest.Changes) - 1; i >= 0; i-- {
+			c := manifest.Changes[i]
+			if dryRun {
+				out.infof("[DRY RUN]   - %s %s\n", undoVerbs[c.Action][1], c.Path)
+				continue
+			}
+			if err := c.undo(); err != nil {
+				out.errorf("Error rolling back %s: %v\n", c.Path, err)
+				errs = append(errs, err)
+				failed = true
+				continue
+			}
+			out.infof("  %s: %s\n", undoVerbs[c.Action][0], c.Path)
+		}
+		if !dryRun && !failed {
+			if err := os.Remove(filepath.Join(root, RollbackManifestName)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
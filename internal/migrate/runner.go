@@ -0,0 +1,201 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Package runners that can execute Biome without a local install. runnerAuto
+// picks one per location from the lockfile next to it.
+const (
+	runnerAuto = "auto"
+	runnerNpx  = "npx"
+	runnerPnpm = "pnpm"
+	runnerYarn = "yarn"
+	runnerBun  = "bun"
+)
+
+var runners = []string{runnerAuto, runnerNpx, runnerPnpm, runnerYarn, runnerBun}
+
+// errAmbiguousRunner fails a location whose lockfiles don't settle which
+// runner -runner auto should use.
+var errAmbiguousRunner = errors.New("-runner is required: lockfiles of several package managers make runner detection ambiguous")
+
+// lockfileRunners maps each package manager lockfile to its runner.
+var lockfileRunners = map[string]string{
+	"package-lock.json":   runnerNpx,
+	"npm-shrinkwrap.json": runnerNpx,
+	"pnpm-lock.yaml":      runnerPnpm,
+	"yarn.lock":           runnerYarn,
+	"bun.lockb":           runnerBun,
+	"bun.lock":            runnerBun,
+}
+
+// runnerLimits caps how many biome commands each package runner runs at
+// once, since parallel npx, pnpm dlx, yarn dlx or bunx invocations contend
+// for the runner's package cache. Commands of a local biome binary are not
+// limited. A nil runnerLimits limits nothing.
+type runnerLimits map[string]chan struct{}
+
+// newRunnerLimits allows n commands of each runner at once.
+func newRunnerLimits(n int) runnerLimits {
+	limits := make(runnerLimits)
+	for _, runner := range runners {
+		if runner != runnerAuto {
+			limits[runner] = make(chan struct{}, n)
+		}
+	}
+	return limits
+}
+
+// acquire waits until runner may start another command and returns the
+// function that frees its slot again. It gives up once ctx is done.
+func (l runnerLimits) acquire(ctx context.Context, runner string) (release func(), err error) {
+	slots, ok := l[runner]
+	if !ok {
+		return func() {}, nil
+	}
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// detectRunner picks the runner for dir from the nearest directory at or
+// above it that has a lockfile. When that directory has lockfiles of more
+// than one package manager, the choice is ambiguous: no runner is picked and
+// the conflicting lockfiles are returned instead.
+func detectRunner(dir string) (runner string, conflict []string) {
+	for d := dir; ; d = filepath.Dir(d) {
+		var found []string
+		for name := range lockfileRunners {
+			if _, err := os.Stat(filepath.Join(d, name)); err == nil {
+				found = append(found, name)
+			}
+		}
+
+		if len(found) > 0 {
+			slices.Sort(found)
+			runner = lockfileRunners[found[0]]
+			for _, name := range found[1:] {
+				if lockfileRunners[name] != runner {
+					for _, name := range found {
+						conflict = append(conflict, filepath.Join(d, name))
+					}
+					return "", conflict
+				}
+			}
+			return runner, nil
+		}
+
+		if filepath.Dir(d) == d {
+			return runnerNpx, nil
+		}
+	}
+}
+
+// useLocalBiome reports whether locally installed biome binaries are
+// preferred over the runner. A pinned -biome-version can only be honored by
+// the runner.
+func useLocalBiome(opts *Options) bool {
+	return !opts.UseNpx && opts.BiomeVersion == ""
+}
+
+// localBiome returns the biome executable to run for dir: the nearest
+// node_modules/.bin/biome at or above dir, up to root, or else biome on
+// PATH. It returns "" when there is neither.
+func localBiome(dir, root string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		path := filepath.Join(d, "node_modules", ".bin", "biome")
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() && info.Mode()&0o111 != 0 {
+			return path
+		}
+		if d == root || filepath.Dir(d) == d {
+			break
+		}
+	}
+	if path, err := exec.LookPath("biome"); err == nil {
+		return path
+	}
+	return ""
+}
+
+// biomeFor returns opts with the way Biome runs in loc settled: a local
+// biome binary when there is one, otherwise the runner, picked from the
+// lockfiles with -runner auto. conflict lists the lockfiles that made that
+// pick ambiguous, in which case the runner is left unset.
+func biomeFor(loc *ConfigLocation, opts *Options) (resolved *Options, conflict []string) {
+	if opts.binary != "" || (opts.Runner != runnerAuto && !useLocalBiome(opts)) {
+		return opts, nil
+	}
+
+	copied := *opts
+	if useLocalBiome(opts) {
+		copied.binary = localBiome(loc.refDir(), loc.Root)
+	}
+	if copied.binary == "" && copied.Runner == runnerAuto {
+		copied.Runner, conflict = detectRunner(loc.refDir())
+	}
+	return &copied, conflict
+}
+
+// runnerCommand returns the program and leading arguments that make runner
+// execute pkg.
+func runnerCommand(runner, pkg string) (string, []string) {
+	switch runner {
+	case runnerPnpm:
+		return "pnpm", []string{"dlx", pkg}
+	case runnerYarn:
+		return "yarn", []string{"dlx", pkg}
+	case runnerBun:
+		return "bunx", []string{pkg}
+	default:
+		return "npx", []string{pkg}
+	}
+}
+
+// preflightRunners checks that Biome can be run for each of locs before any
+// of them is touched: the runner must be on PATH and "biome --version" must
+// succeed. Every local binary and, with -runner auto, every runner picked
+// for locs is checked once.
+func preflightRunners(ctx context.Context, locs []*ConfigLocation, opts *Options, out *printer) error {
+	checked := make(map[string]bool)
+	for _, loc := range locs {
+		// A location without a runner fails on its own with
+		// errAmbiguousRunner, which shouldn't stop the others.
+		resolved, conflict := biomeFor(loc, opts)
+		if len(conflict) > 0 {
+			continue
+		}
+		name, _ := runnerCommand(resolved.Runner, biomePackage(resolved))
+		what := biomePackage(resolved)
+		if resolved.binary != "" {
+			name, what = resolved.binary, resolved.binary
+		}
+		if checked[name] {
+			continue
+		}
+		checked[name] = true
+
+		if resolved.binary == "" {
+			if _, err := exec.LookPath(name); err != nil {
+				return fmt.Errorf("%s is not on PATH; install it or pick another runner with -runner (%s)", name, strings.Join(runners, ", "))
+			}
+		}
+		output, err := runBiomeCapture(ctx, loc.Dir, resolved, out, "--version")
+		if err != nil {
+			return fmt.Errorf("%s could not run %s --version: %v\n%s", name, what, err, bytes.TrimSpace(output))
+		}
+		out.verbosef("Using %s via %s\n", bytes.TrimSpace(output), name)
+	}
+	return nil
+}
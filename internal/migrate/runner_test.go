@@ -0,0 +1,100 @@
+package migrate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDetectRunner(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        []string
+		dir          string
+		want         string
+		wantConflict []string
+	}{
+		{name: "no lockfile", dir: "app", want: runnerNpx},
+		{name: "npm", files: []string{"app/package-lock.json"}, dir: "app", want: runnerNpx},
+		{name: "pnpm", files: []string{"app/pnpm-lock.yaml"}, dir: "app", want: runnerPnpm},
+		{name: "yarn", files: []string{"app/yarn.lock"}, dir: "app", want: runnerYarn},
+		{name: "bun", files: []string{"app/bun.lock"}, dir: "app", want: runnerBun},
+		{name: "from a parent", files: []string{"pnpm-lock.yaml"}, dir: "packages/web", want: runnerPnpm},
+		{name: "nearest wins", files: []string{"pnpm-lock.yaml", "packages/web/yarn.lock"}, dir: "packages/web", want: runnerYarn},
+		{name: "same manager twice", files: []string{"app/bun.lock", "app/bun.lockb"}, dir: "app", want: runnerBun},
+		{
+			name:         "ambiguous",
+			files:        []string{"app/package-lock.json", "app/yarn.lock"},
+			dir:          "app",
+			wantConflict: []string{"app/package-lock.json", "app/yarn.lock"},
+		},
+		{
+			name:         "ambiguous parent",
+			files:        []string{"pnpm-lock.yaml", "yarn.lock"},
+			dir:          "packages/web",
+			wantConflict: []string{"pnpm-lock.yaml", "yarn.lock"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			files := map[string]string{tt.dir + "/.eslintrc.json": `{}`}
+			for _, name := range tt.files {
+				files[name] = ""
+			}
+			writeFiles(t, root, files)
+
+			runner, conflict := detectRunner(filepath.Join(root, filepath.FromSlash(tt.dir)))
+			var wantConflict []string
+			for _, name := range tt.wantConflict {
+				wantConflict = append(wantConflict, filepath.Join(root, filepath.FromSlash(name)))
+			}
+			if runner != tt.want || !reflect.DeepEqual(conflict, wantConflict) {
+				t.Errorf("detectRunner = %q, %v, want %q, %v", runner, conflict, tt.want, wantConflict)
+			}
+		})
+	}
+}
+
+// An explicit -runner is used as is, whatever the lockfiles say.
+func TestBiomeForExplicitRunner(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"package-lock.json": "", "yarn.lock": ""})
+	loc := &ConfigLocation{Dir: root, Root: root}
+
+	for _, runner := range []string{runnerNpx, runnerPnpm, runnerYarn, runnerBun} {
+		resolved, conflict := biomeFor(loc, &Options{Runner: runner, UseNpx: true})
+		if resolved.Runner != runner || conflict != nil {
+			t.Errorf("biomeFor with -runner %s = %q, %v, want %q, no conflict", runner, resolved.Runner, conflict, runner)
+		}
+	}
+
+	resolved, conflict := biomeFor(loc, &Options{Runner: runnerAuto, UseNpx: true})
+	if resolved.Runner != "" || len(conflict) != 2 {
+		t.Errorf("biomeFor with -runner auto = %q, %v, want no runner and both lockfiles", resolved.Runner, conflict)
+	}
+}
+
+// A location whose runner is ambiguous fails before anything is written.
+func TestMigrateAmbiguousRunner(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{".eslintrc.json": `{}`, "package-lock.json": "", "pnpm-lock.yaml": ""})
+	locations, err := FindConfigs(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc := locations[root]
+	if loc == nil {
+		t.Fatalf("FindConfigs missed %s", root)
+	}
+
+	err = Migrate(loc, Options{UseNpx: true})
+	if !errors.Is(err, errAmbiguousRunner) {
+		t.Errorf("Migrate = %v, want %v", err, errAmbiguousRunner)
+	}
+	if _, err := os.Stat(filepath.Join(root, "biome.json")); err == nil {
+		t.Error("Migrate wrote biome.json")
+	}
+}
@@ -0,0 +1,183 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeFiles creates each file under root with its content, making the
+// parent directories as needed.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// Patterns given with a relative -input match paths relative to the input
+// directory, not to the working directory the input was relative to.
+func TestRelativeInputPatterns(t *testing.T) {
+	work := t.TempDir()
+	writeFiles(t, work, map[string]string{
+		"repo/build/packages/web/.eslintrc.json":      `{}`,
+		"repo/build/packages/web/dist/.eslintrc.json": `{}`,
+		"repo/build/packages/web/tmp/.eslintrc.json":  `{}`,
+		"repo/build/packages/api/.prettierrc":         `{"semi": false}`,
+		"repo/build/legacy/app/.eslintrc.json":        `{}`,
+		"repo/build/tools/.eslintrc.json":             `{}`,
+		"repo/build/node_modules/lib/.eslintrc.json":  `{}`,
+		"other/.keep": ``,
+	})
+
+	tests := []struct {
+		name, cwd, input string
+	}{
+		{"below cwd", work, "repo/build"},
+		{"dot prefix", work, "./repo/build/"},
+		{"through parent", filepath.Join(work, "other"), "../repo/build"},
+		{"cwd itself", filepath.Join(work, "repo", "build"), "."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(tt.cwd)
+			roots, _, err := inputRoots([]string{tt.input}, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			root := roots[0]
+			if want := filepath.Join(work, "repo", "build"); root != want {
+				t.Fatalf("root = %s, want %s", root, want)
+			}
+
+			locations, err := findConfigs(root, scanOptions{skipDirs: defaultSkipDirs, ignore: []string{"packages/*/tmp", "/legacy"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantDirs := map[string]bool{
+				"packages/web":      true,
+				"packages/web/dist": false,
+				"packages/web/tmp":  false,
+				"packages/api":      true,
+				"legacy/app":        false,
+				"tools":             true,
+				"node_modules/lib":  false,
+			}
+			for dir, want := range wantDirs {
+				if got := locations[filepath.Join(root, filepath.FromSlash(dir))] != nil; got != want {
+					t.Errorf("found %s = %v, want %v", dir, got, want)
+				}
+			}
+
+			filter, err := parseDirFilter("pathMatches('packages/*') && depth == 2")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var matched []string
+			for _, loc := range locations {
+				if attrs := locationAttrs(loc); filter(attrs) {
+					matched = append(matched, attrs.relPath)
+				}
+			}
+			slices.Sort(matched)
+			if want := []string{"packages/api", "packages/web"}; !slices.Equal(matched, want) {
+				t.Errorf("-dir-filter matched %v, want %v", matched, want)
+			}
+		})
+	}
+}
+
+func TestFindConfigsSkipsHiddenDirs(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"app/.eslintrc.json":    `{"rules": {"semi": "error"}}`,
+		".cache/.eslintrc.json": `{"rules": {"semi": "error"}}`,
+		"dist/.eslintrc.json":   `{"rules": {"semi": "error"}}`,
+	})
+
+	locations, err := FindConfigs(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locations[filepath.Join(root, "app")] == nil {
+		t.Errorf("FindConfigs missed app, found %v", keys(locations))
+	}
+	for _, dir := range []string{".cache", "dist"} {
+		if locations[filepath.Join(root, dir)] != nil {
+			t.Errorf("FindConfigs picked up %s by default", dir)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		opts     scanOptions
+		wantDirs map[string]bool
+	}{
+		{
+			name:     "skip-dirs replaced",
+			opts:     scanOptions{skipDirs: nil},
+			wantDirs: map[string]bool{"app": true, ".cache": false, "dist": true},
+		},
+		{
+			name:     "include-hidden",
+			opts:     scanOptions{skipDirs: defaultSkipDirs, includeHidden: true},
+			wantDirs: map[string]bool{"app": true, ".cache": true, "dist": false},
+		},
+		{
+			name:     "skip-dirs replaced and include-hidden",
+			opts:     scanOptions{includeHidden: true},
+			wantDirs: map[string]bool{"app": true, ".cache": true, "dist": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locations, err := findConfigs(root, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for dir, want := range tt.wantDirs {
+				if got := locations[filepath.Join(root, dir)] != nil; got != want {
+					t.Errorf("found %s = %v, want %v", dir, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestInputList0KeepsNewlines(t *testing.T) {
+	work := t.TempDir()
+	for _, dir := range []string{"plain", "with\nnewline", "trailing\n"} {
+		if err := os.Mkdir(filepath.Join(work, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list := filepath.Join(work, "list")
+	entries := filepath.Join(work, "plain") + "\x00" + filepath.Join(work, "with\nnewline") + "\x00" + filepath.Join(work, "trailing\n") + "\x00"
+	if err := os.WriteFile(list, []byte(entries), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	roots, _, err := inputRoots(nil, list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(work, "plain"), filepath.Join(work, "with\nnewline"), filepath.Join(work, "trailing\n")}
+	if !slices.Equal(roots, want) {
+		t.Errorf("roots = %q, want %q", roots, want)
+	}
+}
+
+func keys(locations map[string]*ConfigLocation) []string {
+	var dirs []string
+	for dir := range locations {
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
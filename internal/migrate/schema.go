@@ -0,0 +1,379 @@
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// biomeSchemaFile is where an installed @biomejs/biome package keeps its
+// configuration schema, relative to the directory holding node_modules.
+var biomeSchemaFile = filepath.Join("node_modules", "@biomejs", "biome", "configuration_schema.json")
+
+// jsonSchema is a parsed JSON Schema document. Only the keywords the Biome
+// configuration schema relies on are checked: $ref, type, enum, const,
+// properties, additionalProperties, required, items, allOf, anyOf, oneOf,
+// minimum and maximum. Annotations like description or format are ignored.
+type jsonSchema struct {
+	root any
+}
+
+// schemaViolation is one value of a config that doesn't match the schema,
+// located by its RFC 6901 JSON Pointer.
+type schemaViolation struct {
+	pointer string
+	message string
+}
+
+func (v schemaViolation) String() string {
+	if v.pointer == "" {
+		return "(root): " + v.message
+	}
+	return v.pointer + ": " + v.message
+}
+
+// loadSchema reads the JSON Schema at path.
+func loadSchema(path string) (*jsonSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var root any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &jsonSchema{root: root}, nil
+}
+
+// findSchema returns the schema file the config of dir is validated
+// against: -local-schema when set, otherwise the schema shipped with the
+// @biomejs/biome package installed nearest to dir.
+func findSchema(dir string, opts *Options) (string, bool) {
+	if opts.LocalSchema != "" {
+		return opts.LocalSchema, true
+	}
+	for d := dir; ; d = filepath.Dir(d) {
+		path := filepath.Join(d, biomeSchemaFile)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+		if filepath.Dir(d) == d {
+			return "", false
+		}
+	}
+}
+
+// schemaCache loads each schema file once, however many locations share
+// it. It is safe for concurrent use.
+type schemaCache struct {
+	mu      sync.Mutex
+	schemas map[string]*jsonSchema
+}
+
+func (c *schemaCache) load(path string) (*jsonSchema, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if schema, ok := c.schemas[path]; ok {
+		return schema, nil
+	}
+	schema, err := loadSchema(path)
+	if err != nil {
+		return nil, err
+	}
+	if c.schemas == nil {
+		c.schemas = make(map[string]*jsonSchema)
+	}
+	c.schemas[path] = schema
+	return schema, nil
+}
+
+// validateProposed checks the biome.json a real run would propose for dir
+// against the Biome configuration schema and prints each violation with its
+// JSON pointer. What biome migrate adds can't be known without running it,
+// so the proposal is the existing biome.json, or the initial config a run
+// writes, with the built-in patches, overlays and -json-patch applied. That
+// covers what the tool itself contributes, e.g. a bad overlay or template.
+func validateProposed(res *locationResult, dir string, opts *Options, out *printer) {
+	path := existingConfigPath(dir, opts)
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil && !isValidConfigFile(path):
+		return
+	case err != nil && opts.NoMinimalConfig:
+		data = []byte("{}")
+	case err != nil:
+		if data, err = initialConfig(dir, opts); err != nil {
+			return
+		}
+	}
+
+	proposed, err := patchConfig(data, nil, legacyIgnorePatterns(res.loc), res.loc.refDir(), res.loc.Root, opts)
+	if err != nil {
+		out.errorf("[DRY RUN]   - Could not build the proposed %s for %s: %v\n", configFileName(opts), dir, err)
+		res.addError(fmt.Errorf("validate: %w", err))
+		return
+	}
+
+	schemaPath, ok := findSchema(dir, opts)
+	if !ok {
+		out.errorf("[DRY RUN]   - No Biome schema found to validate %s against; install @biomejs/biome or pass -local-schema\n", dir)
+		return
+	}
+	schema, err := opts.schemas.load(schemaPath)
+	if err != nil {
+		out.errorf("Error reading schema %s: %v\n", schemaPath, err)
+		res.addError(fmt.Errorf("validate: %w", err))
+		return
+	}
+
+	var doc any
+	if err := decodeConfig(proposed, opts.Emit == emitJSONC, &doc); err != nil {
+		res.addError(fmt.Errorf("validate: %w", err))
+		return
+	}
+	res.schemaViolations = schema.validate(doc)
+	if len(res.schemaViolations) == 0 {
+		out.infof("[DRY RUN]   - Proposed %s matches %s\n", configFileName(opts), schemaPath)
+		return
+	}
+	out.errorf("[DRY RUN]   - Proposed %s for %s does not match %s:\n", configFileName(opts), dir, schemaPath)
+	for _, v := range res.schemaViolations {
+		out.errorf("[DRY RUN]       %s\n", v)
+	}
+}
+
+// validate checks doc, a decoded JSON value, against the schema and
+// returns its violations ordered by pointer.
+func (s *jsonSchema) validate(doc any) []schemaViolation {
+	violations := s.check(s.root, doc, nil)
+	slices.SortStableFunc(violations, func(a, b schemaViolation) int {
+		return strings.Compare(a.pointer, b.pointer)
+	})
+	return violations
+}
+
+func (s *jsonSchema) check(schema, value any, path []string) []schemaViolation {
+	node, ok := schema.(map[string]any)
+	if !ok {
+		if allowed, ok := schema.(bool); ok && !allowed {
+			return []schemaViolation{violation(path, "not allowed here")}
+		}
+		return nil
+	}
+
+	var violations []schemaViolation
+	if ref, ok := node["$ref"].(string); ok {
+		target, err := s.resolve(ref)
+		if err != nil {
+			return []schemaViolation{violation(path, err.Error())}
+		}
+		violations = append(violations, s.check(target, value, path)...)
+	}
+
+	if types, ok := node["type"]; ok && !matchesType(types, value) {
+		return append(violations, violation(path, fmt.Sprintf("expected %s, got %s", typeNames(types), jsonType(value))))
+	}
+	if enum, ok := node["enum"].([]any); ok && !slices.ContainsFunc(enum, func(e any) bool { return reflect.DeepEqual(e, value) }) {
+		violations = append(violations, violation(path, "must be one of "+jsonValues(enum)))
+	}
+	if want, ok := node["const"]; ok && !reflect.DeepEqual(want, value) {
+		violations = append(violations, violation(path, "must be "+jsonValues([]any{want})))
+	}
+	if n, ok := value.(float64); ok {
+		if minimum, ok := node["minimum"].(float64); ok && n < minimum {
+			violations = append(violations, violation(path, fmt.Sprintf("must be at least %v", minimum)))
+		}
+		if maximum, ok := node["maximum"].(float64); ok && n > maximum {
+			violations = append(violations, violation(path, fmt.Sprintf("must be at most %v", maximum)))
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		violations = append(violations, s.checkObject(node, value, path)...)
+	case []any:
+		if items, ok := node["items"]; ok {
+			for i, item := range value {
+				violations = append(violations, s.check(items, item, childPath(path, strconv.Itoa(i)))...)
+			}
+		}
+	}
+
+	if all, ok := node["allOf"].([]any); ok {
+		for _, branch := range all {
+			violations = append(violations, s.check(branch, value, path)...)
+		}
+	}
+	if branches, ok := node["anyOf"].([]any); ok {
+		violations = append(violations, s.checkBranches(branches, value, path, false)...)
+	}
+	if branches, ok := node["oneOf"].([]any); ok {
+		violations = append(violations, s.checkBranches(branches, value, path, true)...)
+	}
+	return violations
+}
+
+func (s *jsonSchema) checkObject(node, object map[string]any, path []string) []schemaViolation {
+	var violations []schemaViolation
+	if required, ok := node["required"].([]any); ok {
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				if _, ok := object[name]; !ok {
+					violations = append(violations, violation(path, fmt.Sprintf("missing required property %q", name)))
+				}
+			}
+		}
+	}
+
+	properties, _ := node["properties"].(map[string]any)
+	additional, hasAdditional := node["additionalProperties"]
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		child := childPath(path, key)
+		if property, ok := properties[key]; ok {
+			violations = append(violations, s.check(property, object[key], child)...)
+		} else if allowed, ok := additional.(bool); ok && !allowed {
+			violations = append(violations, violation(child, "unknown property"))
+		} else if hasAdditional {
+			violations = append(violations, s.check(additional, object[key], child)...)
+		}
+	}
+	return violations
+}
+
+// checkBranches implements anyOf and, with exactlyOne, oneOf. When no
+// branch matches, the violations of the branch that got furthest into the
+// value explain the mismatch best: a branch that accepted an object but
+// rejected one of its properties points right at that property.
+func (s *jsonSchema) checkBranches(branches []any, value any, path []string, exactlyOne bool) []schemaViolation {
+	matched := 0
+	var closest []schemaViolation
+	for _, branch := range branches {
+		violations := s.check(branch, value, path)
+		if len(violations) == 0 {
+			matched++
+			continue
+		}
+		if below(violations, path) && (closest == nil || len(violations) < len(closest)) {
+			closest = violations
+		}
+	}
+
+	switch {
+	case matched == 0 && closest != nil:
+		return closest
+	case matched == 0:
+		return []schemaViolation{violation(path, "does not match any of the allowed schemas")}
+	case exactlyOne && matched > 1:
+		return []schemaViolation{violation(path, "matches more than one of the allowed schemas")}
+	}
+	return nil
+}
+
+// resolve looks up a $ref within the schema document.
+func (s *jsonSchema) resolve(ref string) (any, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("unsupported schema reference %q", ref)
+	}
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	target, err := pointerGet(s.root, tokens)
+	if err != nil {
+		return nil, fmt.Errorf("schema reference %q: %w", ref, err)
+	}
+	return target, nil
+}
+
+func violation(path []string, message string) schemaViolation {
+	return schemaViolation{pointer: formatPointer(path), message: message}
+}
+
+// childPath returns path extended by token without sharing path's backing
+// array, so sibling paths don't overwrite each other.
+func childPath(path []string, token string) []string {
+	return append(path[:len(path):len(path)], token)
+}
+
+// below reports whether every violation lies strictly inside path.
+func below(violations []schemaViolation, path []string) bool {
+	prefix := formatPointer(path) + "/"
+	for _, v := range violations {
+		if !strings.HasPrefix(v.pointer, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesType(types, value any) bool {
+	switch types := types.(type) {
+	case string:
+		return isType(types, value)
+	case []any:
+		return slices.ContainsFunc(types, func(t any) bool {
+			name, _ := t.(string)
+			return isType(name, value)
+		})
+	}
+	return true
+}
+
+func isType(name string, value any) bool {
+	if name == "integer" {
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	}
+	return name == jsonType(value)
+}
+
+// jsonType names the JSON type of a value decoded by encoding/json.
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func typeNames(types any) string {
+	if list, ok := types.([]any); ok {
+		names := make([]string, len(list))
+		for i, t := range list {
+			names[i] = fmt.Sprint(t)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(types)
+}
+
+func jsonValues(values []any) string {
+	encoded := make([]string, len(values))
+	for i, v := range values {
+		data, _ := json.Marshal(v)
+		encoded[i] = string(data)
+	}
+	return strings.Join(encoded, ", ")
+}
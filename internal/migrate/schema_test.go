@@ -0,0 +1,131 @@
+package migrate
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// testSchema mimics how the Biome configuration schema is laid out:
+// definitions referenced through $ref, nullable anyOf wrappers and closed
+// objects.
+const testSchema = `{
+  "type": "object",
+  "properties": {
+    "$schema": {"type": ["string", "null"]},
+    "formatter": {"anyOf": [{"$ref": "#/definitions/Formatter"}, {"type": "null"}]},
+    "linter": {"anyOf": [{"$ref": "#/definitions/Linter"}, {"type": "null"}]},
+    "overrides": {"type": "array", "items": {"$ref": "#/definitions/Override"}}
+  },
+  "additionalProperties": false,
+  "definitions": {
+    "Formatter": {
+      "type": "object",
+      "properties": {
+        "enabled": {"type": "boolean"},
+        "indentStyle": {"enum": ["tab", "space"]},
+        "lineWidth": {"type": "integer", "minimum": 1, "maximum": 320}
+      },
+      "additionalProperties": false
+    },
+    "Linter": {
+      "type": "object",
+      "properties": {
+        "rules": {"type": "object", "additionalProperties": {"$ref": "#/definitions/RuleLevel"}}
+      },
+      "additionalProperties": false
+    },
+    "RuleLevel": {"oneOf": [{"const": "error"}, {"const": "warn"}, {"const": "off"}]},
+    "Override": {
+      "type": "object",
+      "required": ["include"],
+      "properties": {"include": {"type": "array", "items": {"type": "string"}}},
+      "additionalProperties": false
+    }
+  }
+}`
+
+func TestSchemaValidate(t *testing.T) {
+	var root any
+	if err := json.Unmarshal([]byte(testSchema), &root); err != nil {
+		t.Fatal(err)
+	}
+	schema := &jsonSchema{root: root}
+
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{
+			name:   "valid",
+			config: `{"$schema": "./schema.json", "formatter": {"enabled": true, "lineWidth": 100}, "linter": {"rules": {"noVar": "warn"}}}`,
+		},
+		{
+			name:   "nullable section",
+			config: `{"formatter": null}`,
+		},
+		{
+			name:   "unknown top-level key",
+			config: `{"formater": {}}`,
+			want:   []string{"/formater: unknown property"},
+		},
+		{
+			name:   "unknown key inside a referenced definition",
+			config: `{"formatter": {"indentWidht": 2}}`,
+			want:   []string{"/formatter/indentWidht: unknown property"},
+		},
+		{
+			name:   "wrong type, enum and range",
+			config: `{"formatter": {"enabled": "yes", "indentStyle": "tabs", "lineWidth": 1000}}`,
+			want: []string{
+				`/formatter/enabled: expected boolean, got string`,
+				`/formatter/indentStyle: must be one of "tab", "space"`,
+				`/formatter/lineWidth: must be at most 320`,
+			},
+		},
+		{
+			name:   "not an integer",
+			config: `{"formatter": {"lineWidth": 80.5}}`,
+			want:   []string{"/formatter/lineWidth: expected integer, got number"},
+		},
+		{
+			name:   "rule level in additionalProperties",
+			config: `{"linter": {"rules": {"noVar": "warning"}}}`,
+			want:   []string{"/linter/rules/noVar: does not match any of the allowed schemas"},
+		},
+		{
+			name:   "array items and required",
+			config: `{"overrides": [{"include": ["a/**"]}, {"include": [1]}, {}]}`,
+			want: []string{
+				"/overrides/1/include/0: expected string, got number",
+				`/overrides/2: missing required property "include"`,
+			},
+		},
+		{
+			name:   "pointer escaping",
+			config: `{"a/b~c": true}`,
+			want:   []string{"/a~1b~0c: unknown property"},
+		},
+		{
+			name:   "root of the wrong type",
+			config: `[]`,
+			want:   []string{"(root): expected object, got array"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc any
+			if err := json.Unmarshal([]byte(tt.config), &doc); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, v := range schema.validate(doc) {
+				got = append(got, v.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package migrate
+
+import "fmt"
+
+// runStats is the end-of-run scorecard.
+type runStats struct {
+	found int
+	// withTool counts the locations that have each tool's config.
+	withTool map[string]int
+	// succeeded and failed count each tool's migrations, and disabled the
+	// ones skipped because the config was empty or switched off.
+	succeeded map[string]int
+	failed    map[string]int
+	disabled  map[string]int
+	// created and existing count the Biome configs written, split by
+	// whether the location had one before.
+	created  int
+	existing int
+	skipped  int
+	// processed counts the results, skipped ones included. Locations that
+	// weren't reached because the run stopped early have none.
+	processed int
+}
+
+// statsTools are the tools the scorecard reports on, in order.
+var statsTools = []struct{ key, name string }{
+	{"eslint", "ESLint"},
+	{"prettier", "Prettier"},
+	{"stylelint", "Stylelint"},
+}
+
+// collectStats tallies the locations found and the results of those that
+// were processed. In dry-run the results describe what a real run would do.
+func collectStats(locations map[string]*ConfigLocation, results []*locationResult, dryRun bool) runStats {
+	stats := runStats{
+		found:     len(locations),
+		withTool:  make(map[string]int),
+		succeeded: make(map[string]int),
+		failed:    make(map[string]int),
+		disabled:  make(map[string]int),
+		processed: len(results),
+	}
+	for _, loc := range locations {
+		for _, tool := range loc.tools() {
+			stats.withTool[tool]++
+		}
+	}
+
+	for _, res := range results {
+		if res.skipped {
+			stats.skipped++
+			continue
+		}
+		for _, tool := range res.succeeded {
+			stats.succeeded[tool]++
+		}
+		for _, tool := range res.failedTools {
+			stats.failed[tool]++
+		}
+		for _, tool := range res.disabledTools {
+			stats.disabled[tool]++
+		}
+		if res.migrated || (dryRun && !res.failed()) {
+			if res.created {
+				stats.created++
+			} else {
+				stats.existing++
+			}
+		}
+	}
+	return stats
+}
+
+// printStats prints the scorecard. Stylelint only shows up when some
+// location has a Stylelint config.
+func printStats(out *printer, stats runStats, dryRun bool, configName string) {
+	verb, created, existing := "succeeded", "created", "already existed"
+	if dryRun {
+		verb, created, existing = "would succeed", "would be created", "would be updated"
+	}
+
+	out.infof("\nSummary:\n")
+	out.infof("  %-22s %d\n", "Locations found:", stats.found)
+	for _, tool := range statsTools {
+		if tool.key == "stylelint" && stats.withTool[tool.key] == 0 {
+			continue
+		}
+		out.infof("  %-22s %d\n", "With "+tool.name+":", stats.withTool[tool.key])
+	}
+	for _, tool := range statsTools {
+		if stats.withTool[tool.key] == 0 {
+			continue
+		}
+		line := fmt.Sprintf("%d %s, %d failed", stats.succeeded[tool.key], verb, stats.failed[tool.key])
+		if n := stats.disabled[tool.key]; n > 0 {
+			line += fmt.Sprintf(", %d disabled", n)
+		}
+		out.infof("  %-22s %s\n", tool.name+" migrations:", line)
+	}
+	out.infof("  %-22s %d %s, %d %s\n", configName+" files:", stats.created, created, stats.existing, existing)
+	if stats.skipped > 0 {
+		out.infof("  %-22s %d\n", "Skipped:", stats.skipped)
+	}
+	if notRun := stats.found - stats.processed; notRun > 0 {
+		out.infof("  %-22s %d\n", "Not processed:", notRun)
+	}
+}
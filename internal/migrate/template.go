@@ -0,0 +1,79 @@
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// configTemplate renders the initial biome.json for a location from a
+// user-supplied -template file.
+type configTemplate struct {
+	tmpl *template.Template
+	vars map[string]string
+}
+
+// loadConfigTemplate parses the Go text/template at path and checks that it
+// renders valid JSON. vars are the key=value pairs given with -template-var.
+func loadConfigTemplate(path string, vars []string) (*configTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := make(map[string]string, len(vars))
+	for _, v := range vars {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("-template-var %q: expected key=value", v)
+		}
+		parsed[key] = value
+	}
+
+	t := &configTemplate{tmpl: tmpl, vars: parsed}
+	// Catch templates that can't produce valid JSON, or that use a
+	// -template-var that wasn't given, before any location is touched.
+	if _, err := t.render("example"); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// render produces the config body for dir. Besides the -template-var
+// values, .DirName holds the directory's name and .Dir its full path. The
+// result must be valid JSON.
+func (t *configTemplate) render(dir string) ([]byte, error) {
+	data := make(map[string]string, len(t.vars)+2)
+	for key, value := range t.vars {
+		data[key] = value
+	}
+	data["DirName"] = filepath.Base(dir)
+	data["Dir"] = dir
+
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("template %s rendered invalid JSON for %s", t.tmpl.Name(), dir)
+	}
+	return buf.Bytes(), nil
+}
+
+// initialConfig returns the biome.json written before migrating dir: the
+// rendered -template, or minimalBiomeConfig without one.
+func initialConfig(dir string, opts *Options) ([]byte, error) {
+	if opts.template == nil {
+		return []byte(minimalBiomeConfig), nil
+	}
+	return opts.template.render(dir)
+}
@@ -0,0 +1,371 @@
+package migrate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a non-blank line of a YAML document with its comment removed.
+type yamlLine struct {
+	num    int
+	indent int
+	text   string
+}
+
+// parseYAML decodes the subset of YAML that config files use: block
+// mappings and sequences, plain and quoted scalars, and single-line flow
+// sequences and mappings. Anchors, tags, multi-line scalars and multiple
+// documents are rejected. Mappings decode to map[string]any and sequences
+// to []any, as encoding/json would.
+func parseYAML(data []byte) (any, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(string(data), "\n") {
+		text := strings.TrimRight(stripYAMLComment(raw), " \t\r")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %d: tabs can't indent YAML", i+1)
+		}
+		lines = append(lines, yamlLine{num: i + 1, indent: len(text) - len(trimmed), text: trimmed})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	p := &yamlParser{lines: lines}
+	value, err := p.block(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].num)
+	}
+	return value, nil
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// block parses the mapping or sequence starting at the current line, whose
+// entries are indented by indent.
+func (p *yamlParser) block(indent int) (any, error) {
+	if isYAMLSeqItem(p.lines[p.pos].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+func (p *yamlParser) mapping(indent int) (any, error) {
+	m := map[string]any{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		line := p.lines[p.pos]
+		if isYAMLSeqItem(line.text) {
+			return nil, fmt.Errorf("line %d: expected a key, got a sequence item", line.num)
+		}
+		key, rest, ok := splitYAMLKey(line.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key: value", line.num)
+		}
+		p.pos++
+
+		if rest != "" {
+			value, err := yamlScalarOrFlow(rest, line.num)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+			continue
+		}
+
+		// The value is the block below the key: more indented, or a
+		// sequence at the key's own indentation.
+		switch {
+		case p.pos < len(p.lines) && p.lines[p.pos].indent > indent:
+			value, err := p.block(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+		case p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLSeqItem(p.lines[p.pos].text):
+			value, err := p.sequence(indent)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+		default:
+			m[key] = nil
+		}
+	}
+	return m, nil
+}
+
+func (p *yamlParser) sequence(indent int) (any, error) {
+	list := []any{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLSeqItem(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		item := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+
+		switch {
+		case item == "":
+			p.pos++
+			if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+				value, err := p.block(p.lines[p.pos].indent)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, value)
+			} else {
+				list = append(list, nil)
+			}
+		case isYAMLSeqItem(item) || isYAMLMappingEntry(item):
+			// "- key: value" or "- - item" opens a nested block whose first
+			// line starts where the item text does.
+			p.lines[p.pos] = yamlLine{num: line.num, indent: line.indent + len(line.text) - len(item), text: item}
+			value, err := p.block(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		default:
+			p.pos++
+			value, err := yamlScalarOrFlow(item, line.num)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+	}
+	return list, nil
+}
+
+func isYAMLSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func isYAMLMappingEntry(text string) bool {
+	_, _, ok := splitYAMLKey(text)
+	return ok
+}
+
+// splitYAMLKey splits "key: value" into its key and the text after the
+// colon. Keys may be quoted.
+func splitYAMLKey(text string) (key, rest string, ok bool) {
+	if text == "" {
+		return "", "", false
+	}
+	if text[0] == '"' || text[0] == '\'' {
+		end := closingQuote(text, 0)
+		if end < 0 || end+1 >= len(text) || text[end+1] != ':' {
+			return "", "", false
+		}
+		key, err := unquoteYAML(text[:end+1])
+		if err != nil {
+			return "", "", false
+		}
+		after := text[end+2:]
+		if after != "" && after[0] != ' ' {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(after), true
+	}
+	if text[0] == '[' || text[0] == '{' {
+		return "", "", false
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// yamlScalarOrFlow decodes an inline value: a flow collection or a scalar.
+func yamlScalarOrFlow(text string, num int) (any, error) {
+	switch text[0] {
+	case '[', '{':
+		value, rest, err := parseYAMLFlow(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", num, err)
+		}
+		if strings.TrimSpace(rest) != "" {
+			return nil, fmt.Errorf("line %d: unexpected %q after flow collection", num, rest)
+		}
+		return value, nil
+	case '&', '*', '!', '|', '>':
+		return nil, fmt.Errorf("line %d: unsupported YAML feature %q", num, text[:1])
+	}
+	value, err := yamlScalar(text)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %v", num, err)
+	}
+	return value, nil
+}
+
+// parseYAMLFlow parses the flow collection at the start of text and returns
+// it with the remaining text.
+func parseYAMLFlow(text string) (any, string, error) {
+	closing := byte(']')
+	if text[0] == '{' {
+		closing = '}'
+	}
+	var list []any
+	m := map[string]any{}
+	rest := strings.TrimLeft(text[1:], " ")
+	for {
+		if rest == "" {
+			return nil, "", fmt.Errorf("unterminated %q", text[:1])
+		}
+		if rest[0] == closing {
+			break
+		}
+
+		var entry string
+		var value any
+		var err error
+		if rest[0] == '[' || rest[0] == '{' {
+			value, rest, err = parseYAMLFlow(rest)
+			if err != nil {
+				return nil, "", err
+			}
+		} else {
+			entry, rest = splitFlowEntry(rest, closing)
+			if closing == '}' {
+				key, valueText, ok := splitYAMLKey(entry)
+				if !ok {
+					return nil, "", fmt.Errorf("expected key: value in %q", entry)
+				}
+				if valueText == "" {
+					m[key] = nil
+				} else if m[key], err = yamlScalarOrFlow(valueText, 0); err != nil {
+					return nil, "", err
+				}
+			} else if value, err = yamlScalar(entry); err != nil {
+				return nil, "", err
+			}
+		}
+		if closing == ']' {
+			list = append(list, value)
+		}
+
+		rest = strings.TrimLeft(rest, " ")
+		if strings.HasPrefix(rest, ",") {
+			rest = strings.TrimLeft(rest[1:], " ")
+		}
+	}
+
+	if closing == '}' {
+		return m, rest[1:], nil
+	}
+	if list == nil {
+		list = []any{}
+	}
+	return list, rest[1:], nil
+}
+
+// splitFlowEntry returns the text of the flow entry at the start of text,
+// up to the next top-level comma or the closing bracket.
+func splitFlowEntry(text string, closing byte) (entry, rest string) {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case (c == '"' || c == '\'') && opensQuote(text, i):
+			if end := closingQuote(text, i); end >= 0 {
+				i = end
+			}
+		case c == '[' || c == '{':
+			depth++
+		case depth > 0 && (c == ']' || c == '}'):
+			depth--
+		case depth == 0 && (c == ',' || c == closing):
+			return strings.TrimSpace(text[:i]), text[i:]
+		}
+	}
+	return strings.TrimSpace(text), ""
+}
+
+// yamlScalar decodes a plain or quoted scalar.
+func yamlScalar(text string) (any, error) {
+	if text == "" {
+		return nil, nil
+	}
+	if text[0] == '"' || text[0] == '\'' {
+		if end := closingQuote(text, 0); end != len(text)-1 {
+			return nil, fmt.Errorf("malformed quoted string %s", text)
+		}
+		return unquoteYAML(text)
+	}
+
+	switch text {
+	case "null", "Null", "NULL", "~":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if looksNumeric(text) {
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f, nil
+		}
+	}
+	return text, nil
+}
+
+// looksNumeric reports whether text starts like a decimal number, so words
+// ParseFloat also accepts, such as inf or nan, stay strings.
+func looksNumeric(text string) bool {
+	text = strings.TrimLeft(text, "+-")
+	text = strings.TrimPrefix(text, ".")
+	return text != "" && text[0] >= '0' && text[0] <= '9'
+}
+
+// closingQuote returns the index of the quote closing the string that
+// opens at text[start], or -1.
+func closingQuote(text string, start int) int {
+	quote := text[start]
+	for i := start + 1; i < len(text); i++ {
+		switch {
+		case quote == '"' && text[i] == '\\':
+			i++
+		case text[i] == quote && quote == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			i++
+		case text[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// opensQuote reports whether the quote at text[i] starts a quoted string
+// rather than being part of a plain scalar such as don't.
+func opensQuote(text string, i int) bool {
+	return i == 0 || strings.ContainsRune(" \t[{,:", rune(text[i-1]))
+}
+
+func unquoteYAML(text string) (string, error) {
+	if text[0] == '\'' {
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	}
+	return strconv.Unquote(text)
+}
+
+// stripYAMLComment removes a # comment that isn't inside a quoted string.
+// A # only starts a comment at the start of the line or after a space.
+func stripYAMLComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case (c == '"' || c == '\'') && opensQuote(line, i):
+			if end := closingQuote(line, i); end >= 0 {
+				i = end
+			}
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,180 @@
+package migrate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want any
+	}{
+		{
+			name: "empty document",
+			src:  "# only a comment\n---\n",
+			want: nil,
+		},
+		{
+			name: "nested maps",
+			src: `
+root: true
+parserOptions:
+  ecmaVersion: 2022
+  ecmaFeatures:
+    jsx: true
+env:
+  browser: false
+`,
+			want: map[string]any{
+				"root": true,
+				"parserOptions": map[string]any{
+					"ecmaVersion":  2022.0,
+					"ecmaFeatures": map[string]any{"jsx": true},
+				},
+				"env": map[string]any{"browser": false},
+			},
+		},
+		{
+			name: "block sequences",
+			src: `
+extends:
+  - eslint:recommended
+  - plugin:react/recommended
+plugins:
+- react
+overrides:
+  - files: ["*.ts"]
+    rules:
+      semi: off
+  -
+    files: "*.js"
+matrix:
+  - - 1
+    - 2
+`,
+			want: map[string]any{
+				"extends": []any{"eslint:recommended", "plugin:react/recommended"},
+				"plugins": []any{"react"},
+				"overrides": []any{
+					map[string]any{"files": []any{"*.ts"}, "rules": map[string]any{"semi": "off"}},
+					map[string]any{"files": "*.js"},
+				},
+				"matrix": []any{[]any{1.0, 2.0}},
+			},
+		},
+		{
+			name: "flow collections",
+			src: `
+rules:
+  quotes: [error, single, {avoidEscape: true, allowTemplateLiterals: false}]
+  indent: ["warn", 2]
+empty: []
+none: {}
+`,
+			want: map[string]any{
+				"rules": map[string]any{
+					"quotes": []any{"error", "single", map[string]any{"avoidEscape": true, "allowTemplateLiterals": false}},
+					"indent": []any{"warn", 2.0},
+				},
+				"empty": []any{},
+				"none":  map[string]any{},
+			},
+		},
+		{
+			name: "quoted scalars",
+			src: `
+double: "a \"b\" #c"
+single: 'it''s # not a comment'
+"quoted key": yes
+'single key': "x: y"
+escape: "tab\there"
+list: ["a, b", 'c']
+`,
+			want: map[string]any{
+				"double":     `a "b" #c`,
+				"single":     "it's # not a comment",
+				"quoted key": "yes",
+				"single key": "x: y",
+				"escape":     "tab\there",
+				"list":       []any{"a, b", "c"},
+			},
+		},
+		{
+			name: "comments",
+			src: `# header
+semi: true # trailing
+url: http://example.com/#anchor
+# between
+tabWidth: 4
+`,
+			want: map[string]any{
+				"semi":     true,
+				"url":      "http://example.com/#anchor",
+				"tabWidth": 4.0,
+			},
+		},
+		{
+			name: "scalars",
+			src: `
+a: ~
+b: null
+c: -1.5
+d: .5
+e: inf
+f: don't
+g:
+`,
+			want: map[string]any{
+				"a": nil, "b": nil, "c": -1.5, "d": 0.5, "e": "inf", "f": "don't", "g": nil,
+			},
+		},
+		{
+			name: "top-level sequence",
+			src:  "- a\n- b: 1\n",
+			want: []any{"a", map[string]any{"b": 1.0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAML([]byte(tt.src))
+			if err != nil {
+				t.Fatalf("parseYAML: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseYAML =\n  %#v\nwant\n  %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"tab indentation", "a:\n\tb: 1\n"},
+		{"anchor", "a: &base\n  b: 1\n"},
+		{"alias", "a: *base\n"},
+		{"tag", "a: !!str 1\n"},
+		{"literal block scalar", "a: |\n  text\n"},
+		{"folded block scalar", "a: >\n  text\n"},
+		{"unterminated flow sequence", "a: [1, 2\n"},
+		{"unterminated flow mapping", "a: {b: 1\n"},
+		{"text after flow collection", "a: [1] 2\n"},
+		{"flow mapping entry without key", "a: {b}\n"},
+		{"malformed quoted string", "a: \"b\" c\n"},
+		{"missing colon", "a: 1\nb\n"},
+		{"sequence item among keys", "a: 1\n- b\n"},
+		{"bad dedent", "a:\n    b: 1\n  c: 2\n"},
+		{"bad escape", `a: "\q"` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := parseYAML([]byte(tt.src)); err == nil {
+				t.Errorf("parseYAML(%q) = %#v, want an error", tt.src, got)
+			}
+		})
+	}
+}
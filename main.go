@@ -1,271 +1,140 @@
+// Command biome_configurator migrates the ESLint, Prettier and Stylelint
+// configs of a tree to Biome. The work is done by package internal/migrate;
+// this file only parses the flags.
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"flag"
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
-	"slices"
+	"os/signal"
+	"runtime"
 	"strings"
-)
 
-var eslintConfigFiles = []string{
-	".eslintrc.json",
-	".eslintrc.js",
-	".eslintrc.cjs",
-	".eslintrc.yaml",
-	".eslintrc.yml",
-	".eslintrc",
-	"eslint.config.js",
-	"eslint.config.mjs",
-	"eslint.config.cjs",
-}
+	"biome_config_generator/internal/migrate"
+)
 
-var prettierConfigFiles = []string{
-	".prettierrc",
-	".prettierrc.json",
-	".prettierrc.yml",
-	".prettierrc.yaml",
-	".prettierrc.json5",
-	".prettierrc.js",
-	".prettierrc.cjs",
-	".prettierrc.mjs",
-	".prettierrc.toml",
-	"prettier.config.js",
-	"prettier.config.cjs",
-	"prettier.config.mjs",
-}
+// stringList is a flag.Value that collects every occurrence of a repeatable
+// flag in order.
+type stringList []string
 
-const minimalBiomeConfig = `{
-  "linter": {
-    "enabled": true,
-    "rules": {
-      "recommended": true
-    }
-  }
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
 }
-`
 
-type configLocation struct {
-	dir         string
-	hasEslint   bool
-	hasPrettier bool
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
 }
 
 func main() {
-	inputDir := flag.String("input", "", "Input directory to scan for ESLint/Prettier configs")
-	dryRun := flag.Bool("dry-run", false, "Only show what would be done without actually doing it")
+	flag.Usage = usage
+
+	var f migrate.Flags
+	flag.Var((*stringList)(&f.Inputs), "input", "Input directory to scan for ESLint/Prettier configs (repeatable or comma-separated)")
+	flag.StringVar(&f.InputList0, "input-list0", "", "File of NUL-delimited input directories to scan, - for stdin (pairs with find -print0)")
+	flag.BoolVar(&f.DryRun, "dry-run", false, "Only show what would be done without actually doing it")
+	flag.BoolVar(&f.NoMinimalConfig, "no-minimal-config", false, "Don't write a minimal biome.json before migrating; rely on biome migrate to create it")
+	flag.BoolVar(&f.ErrorsOnly, "errors-only", false, "Only print failures, warnings and a one-line summary")
+	flag.BoolVar(&f.ErrorsOnly, "quiet", false, "Same as -errors-only")
+	flag.BoolVar(&f.ErrorsOnly, "q", false, "Same as -errors-only")
+	flag.BoolVar(&f.TrackedOnly, "tracked-only", false, "Only consider config files tracked by git (git ls-files)")
+	flag.StringVar(&f.SkipDirs, "skip-dirs", strings.Join(migrate.DefaultSkipDirs(), ","), "Comma-separated directory names to skip, replacing the default list (node_modules and .git are always skipped)")
+	flag.StringVar(&f.Ignore, "ignore", "", "Comma-separated directory names or globs to skip in addition to -skip-dirs, e.g. out,coverage,packages/*/tmp")
+	flag.BoolVar(&f.IncludeHidden, "include-hidden", false, "Also scan directories whose name starts with a dot, such as .yarn or .cache")
+	flag.BoolVar(&f.NoGitignore, "no-gitignore", false, "Don't skip paths excluded by .gitignore files")
+	flag.BoolVar(&f.SkipSymlinks, "skip-symlinked-configs", false, "Ignore config files that are symlinks, e.g. to a shared monorepo config")
+	flag.StringVar(&f.Aliases, "resolve-aliases", "", "JSON file mapping logical directory paths to the real directories to scan")
+	flag.StringVar(&f.DirFilter, "dir-filter", "", "Only process locations matching this expression, e.g. \"hasEslint && depth <= 2\"")
+	flag.BoolVar(&f.CheckBiome, "check-biome", false, "Report whether Biome is already installed or cached before migrating")
+	flag.StringVar(&f.Report, "report", "", "Write a per-location report to this file (- for stdout)")
+	flag.StringVar(&f.ReportFormat, "report-format", "csv", "Format of the -report file: csv, json")
+	flag.StringVar(&f.Format, "format", "text", "Output format: text, or json for a single JSON report on stdout with progress on stderr")
+	flag.BoolVar(&f.Stdout, "stdout", false, "Migrate a temporary copy of the single -input directory and print the resulting biome.json")
+	flag.BoolVar(&f.DetectOnly, "detect-only", false, "Only list the detected configs and exit")
+	flag.BoolVar(&f.NoDiff, "no-diff", false, "With -dry-run, only list the planned steps instead of migrating a temporary copy and diffing the result")
+	flag.BoolVar(&f.ExitOnChanges, "exit-nonzero-on-changes", false, "With -dry-run, exit non-zero if any biome.json would be created or changed")
+	flag.BoolVar(&f.ValidateSchema, "validate", false, "With -dry-run, check each proposed biome.json against the Biome schema and report violations by JSON pointer")
+	flag.BoolVar(&f.UseNpx, "use-npx", false, "Always run Biome through the package runner, even when node_modules/.bin/biome or biome on PATH exists")
+	flag.BoolVar(&f.Interactive, "interactive", false, "Ask before migrating each location: y, N, a (yes to all remaining) or q (skip the rest); ignored when stdin isn't a terminal")
+	flag.StringVar(&f.OutputDir, "output-dir", "", "Write each biome.json to the same relative path under this directory instead of into the input tree, which is left untouched")
+	flag.BoolVar(&f.Monorepo, "monorepo", false, "Make each config below an input directory extend the input directory's own biome.json instead of standing alone")
+	flag.DurationVar(&f.Timeout, "timeout", 0, "Kill a biome command that runs longer than this, e.g. 2m, and fail its location (0 means no limit)")
+	flag.IntVar(&f.Concurrency, "concurrency", runtime.NumCPU(), "Number of locations migrated in parallel")
+	flag.IntVar(&f.RunnerConcurrency, "runner-concurrency", 1, "Number of biome commands each package runner (npx, pnpm, yarn, bun) runs at once; a local biome binary is not limited")
+	flag.BoolVar(&f.KeepGoing, "keep-going", true, "Keep migrating after a location fails; -keep-going=false stops at the first failure")
+	flag.IntVar(&f.MaxFailures, "max-failures", 0, "Abort once this many locations have failed (0 means never)")
+	flag.StringVar(&f.LogLevel, "log-level", "info", "Least severe messages to print: debug (implies -v), info, warn (like -errors-only) or error")
+	flag.StringVar(&f.LogFormat, "log-format", "plain", "Message format: plain, or text or json for log/slog records; Biome's own output stays unstructured on stderr")
+	flag.BoolVar(&f.Verbose, "v", false, "Verbose output: every step of each location, the Biome commands run and their output")
+	flag.BoolVar(&f.Explain, "explain", false, "Print a one-line rationale for each action taken")
+	flag.BoolVar(&f.FromPackageRoot, "migrate-from-package-root", false, "Run biome migrate from the nearest ancestor containing package.json")
+	flag.BoolVar(&f.OverwriteInvalid, "overwrite-invalid", false, "Replace an existing biome.json that isn't valid JSON instead of skipping the location")
+	flag.StringVar(&f.BiomeVersion, "biome-version", "", "Pin the Biome version used for migration, e.g. 1.9.4")
+	flag.StringVar(&f.Runner, "runner", "auto", "Package runner used to execute Biome: auto, npx, pnpm, yarn, bun")
+	flag.StringVar(&f.LocalSchema, "local-schema", "", "Local Biome schema file to reference from $schema instead of the remote URL")
+	flag.StringVar(&f.Emit, "emit", "json", "Config format to write: json (biome.json) or jsonc (biome.jsonc with a generated-file header)")
+	flag.BoolVar(&f.NoValidate, "no-validate", false, "Don't have Biome load each written biome.json to check it accepts the config (for offline use)")
+	flag.BoolVar(&f.RunCheck, "run-check", false, "Run biome check after each migration and report diagnostic counts (slow)")
+	flag.BoolVar(&f.ResolveSharedConfigs, "resolve-shared-configs", false, "Warn about package.json \"prettier\" keys naming a shared config package and report its settings")
+	flag.BoolVar(&f.UpdateGitignore, "update-gitignore", false, "Add biome.json to the global gitignore (core.excludesfile, set up as ~/.gitignore_global if unset) instead of printing how to")
+	flag.BoolVar(&f.Rollback, "rollback", false, "Undo the last run in each -input directory from its "+migrate.RollbackManifestName+" instead of migrating")
+	flag.BoolVar(&f.Force, "force", false, "Migrate over biome.json files tracked by git, and with -rollback undo files even when they changed after the run")
+	flag.BoolVar(&f.NoManifest, "no-manifest", false, "Don't record the run's changes in "+migrate.RollbackManifestName+" at each input directory (-rollback then has nothing to undo)")
+	flag.BoolVar(&f.Cleanup, "cleanup", false, "Delete the old ESLint/Prettier/Stylelint config files once their migration succeeded")
+	flag.BoolVar(&f.Backup, "backup", false, "Copy biome.json to a timestamped .bak file before migrating over it")
+	flag.BoolVar(&f.CompareEslint, "compare-eslint", false, "Report ESLint rules that have no counterpart in the generated biome.json")
+	flag.BoolVar(&f.MigrateJSON, "migrate-json-reporter", false, "Run biome migrate with --reporter=json and keep its structured output per location")
+	flag.BoolVar(&f.WriteBiomeignore, "write-biomeignore", false, "Write a .biomeignore merged from .eslintignore/.prettierignore next to each biome.json")
+	flag.StringVar(&f.BiomeignoreTemplate, "biomeignore-template", "", "File whose patterns start every .biomeignore written by -write-biomeignore")
+	flag.StringVar(&f.Template, "template", "", "Go text/template file rendered into the initial biome.json instead of the minimal config")
+	flag.Var((*stringList)(&f.TemplateVars), "template-var", "key=value made available to -template as {{.key}} (repeatable)")
+	flag.Var((*stringList)(&f.Overlays), "overlay", "JSON file deep-merged into every biome.json after the built-in patches (repeatable, applied in order)")
+	flag.Var((*stringList)(&f.Overlays), "patch", "Same as -overlay")
+	flag.StringVar(&f.JSONPatch, "json-patch", "", "RFC 6902 JSON Patch file applied to every biome.json after the built-in patches and overlays")
+	flag.StringVar(&f.RunID, "run-id", "", "Identifier for this run, included in reports (default: generated)")
 	flag.Parse()
 
-	if *inputDir == "" {
-		fmt.Println("Usage: biome_configurator -input <directory> [-dry-run]")
-		os.Exit(1)
-	}
-
-	absInputDir, err := filepath.Abs(*inputDir)
-	if err != nil {
-		fmt.Printf("Error resolving input directory: %v\n", err)
-		os.Exit(1)
-	}
-
-	locations, err := findConfigs(absInputDir)
-	if err != nil {
-		fmt.Printf("Error scanning directory: %v\n", err)
-		os.Exit(1)
-	}
-
-	if len(locations) == 0 {
-		fmt.Println("No ESLint or Prettier config files found")
-		return
-	}
-
-	fmt.Printf("Found configs in %d location(s):\n", len(locations))
-	for dir, loc := range locations {
-		flags := []string{}
-		if loc.hasEslint {
-			flags = append(flags, "eslint")
-		}
-		if loc.hasPrettier {
-			flags = append(flags, "prettier")
-		}
-		fmt.Printf("  - %s [%s]\n", dir, strings.Join(flags, ", "))
-	}
-
-	for dir, loc := range locations {
-		if *dryRun {
-			fmt.Printf("\n[DRY RUN] Would migrate in: %s\n", dir)
-			if loc.hasEslint {
-				fmt.Printf("[DRY RUN]   - ESLint migration\n")
-			}
-			if loc.hasPrettier {
-				fmt.Printf("[DRY RUN]   - Prettier migration\n")
-			}
-			continue
-		}
-
-		fmt.Printf("\nMigrating: %s\n", dir)
-
-		biomeConfigPath := filepath.Join(dir, "biome.json")
-		existingBiome := false
-		if _, err := os.Stat(biomeConfigPath); err == nil {
-			existingBiome = true
-		}
-
-		if !existingBiome {
-			if err := os.WriteFile(biomeConfigPath, []byte(minimalBiomeConfig), 0o644); err != nil {
-				fmt.Printf("Error creating biome.json: %v\n", err)
-				continue
-			}
-		}
-
-		migrationFailed := false
-
-		if loc.hasEslint {
-			if err := migrateEslintConfig(dir); err != nil {
-				fmt.Printf("Error migrating ESLint config: %v\n", err)
-				migrationFailed = true
-			} else {
-				fmt.Printf("  ✓ ESLint migrated\n")
-			}
-		}
-
-		if loc.hasPrettier {
-			if err := migratePrettierConfig(dir); err != nil {
-				fmt.Printf("Error migrating Prettier config: %v\n", err)
-				migrationFailed = true
-			} else {
-				fmt.Printf("  ✓ Prettier migrated\n")
-			}
-		}
-
-		if migrationFailed && !existingBiome && !loc.hasEslint && !loc.hasPrettier {
-			os.Remove(biomeConfigPath)
-			continue
-		}
-
-		if err := patchBiomeConfig(biomeConfigPath); err != nil {
-			fmt.Printf("Error patching biome.json: %v\n", err)
-		}
-
-		fmt.Printf("Created: %s\n", biomeConfigPath)
+	if f.Template == "" && flagSet("template") {
+		fmt.Println("Warning: -template is empty, using the built-in minimal biome.json")
 	}
 
-	fmt.Println("\nDone! Make sure 'biome.json' is in your global gitignore:")
-	fmt.Println("  echo 'biome.json' >> ~/.gitignore_global")
-	fmt.Println("  git config --global core.excludesfile ~/.gitignore_global")
+	// Ctrl-C kills the running biome commands and stops starting new
+	// locations. Once it has been pressed, a second one exits right away.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	status := migrate.Run(ctx, f)
+	stop()
+	os.Exit(status)
 }
 
-func findConfigs(root string) (map[string]*configLocation, error) {
-	locations := make(map[string]*configLocation)
-
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			if os.IsPermission(err) {
-				return filepath.SkipDir
-			}
-			return err
-		}
-
-		if info.IsDir() {
-			name := info.Name()
-			if name == "node_modules" || name == ".git" || name == "dist" || name == "build" || name == ".devops" {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		fileName := info.Name()
-		dir := filepath.Dir(path)
-
-		if slices.Contains(eslintConfigFiles, fileName) {
-			if locations[dir] == nil {
-				locations[dir] = &configLocation{dir: dir}
-			}
-			locations[dir].hasEslint = true
-		}
-
-		if slices.Contains(prettierConfigFiles, fileName) {
-			if locations[dir] == nil {
-				locations[dir] = &configLocation{dir: dir}
-			}
-			locations[dir].hasPrettier = true
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
 		}
-
-		return nil
 	})
-
-	return locations, err
-}
-
-func migrateEslintConfig(dir string) error {
-	cmd := exec.Command("npx", "@biomejs/biome", "migrate", "eslint", "--write")
-	cmd.Dir = dir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	return cmd.Run()
-}
-
-func migratePrettierConfig(dir string) error {
-	cmd := exec.Command("npx", "@biomejs/biome", "migrate", "prettier", "--write")
-	cmd.Dir = dir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	return cmd.Run()
+	return set
 }
 
-func patchBiomeConfig(path string) error {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return err
-	}
-
-	var config map[string]any
-	if err := json.Unmarshal(data, &config); err != nil {
-		return err
-	}
-
-	if formatter, ok := config["formatter"].(map[string]any); ok {
-		formatter["formatWithErrors"] = true
-	} else {
-		config["formatter"] = map[string]any{
-			"formatWithErrors": true,
-		}
-	}
-
-	if js, ok := config["javascript"].(map[string]any); ok {
-		js["parser"] = map[string]any{
-			"unsafeParameterDecoratorsEnabled": true,
-		}
-	} else {
-		config["javascript"] = map[string]any{
-			"parser": map[string]any{
-				"unsafeParameterDecoratorsEnabled": true,
-			},
-		}
-	}
-
-	output, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(path, output, 0o644)
-}
-
-func init() {
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Biome Configurator - Migrate ESLint/Prettier configs to Biome\n\n")
-		fmt.Fprintf(os.Stderr, "Usage:\n")
-		fmt.Fprintf(os.Stderr, "  biome_configurator -input <directory> [options]\n\n")
-		fmt.Fprintf(os.Stderr, "Options:\n")
-		flag.PrintDefaults()
-		fmt.Fprintf(os.Stderr, "\nSupported ESLint config files:\n")
-		fmt.Fprintf(os.Stderr, "  %s\n", strings.Join(eslintConfigFiles, ", "))
-		fmt.Fprintf(os.Stderr, "\nSupported Prettier config files:\n")
-		fmt.Fprintf(os.Stderr, "  %s\n", strings.Join(prettierConfigFiles, ", "))
-	}
+// usage prints the -help text.
+func usage() {
+	fmt.Fprintf(os.Stderr, "Biome Configurator - Migrate ESLint/Prettier configs to Biome\n\n")
+	fmt.Fprintf(os.Stderr, "Usage:\n")
+	fmt.Fprintf(os.Stderr, "  biome_configurator -input <directory> [options]\n\n")
+	fmt.Fprintf(os.Stderr, "Options:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\nSupported ESLint config files:\n")
+	fmt.Fprintf(os.Stderr, "  %s\n", strings.Join(migrate.ConfigFileNames("eslint"), ", "))
+	fmt.Fprintf(os.Stderr, "\nSupported Prettier config files:\n")
+	fmt.Fprintf(os.Stderr, "  %s\n", strings.Join(migrate.ConfigFileNames("prettier"), ", "))
+	fmt.Fprintf(os.Stderr, "\nSupported Stylelint config files:\n")
+	fmt.Fprintf(os.Stderr, "  %s\n", strings.Join(migrate.ConfigFileNames("stylelint"), ", "))
 }